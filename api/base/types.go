@@ -26,4 +26,5 @@ type ModelStatus struct {
 	Owner              string
 	HostedMachineCount int
 	ServiceCount       int
+	VolumeCount        int
 }
@@ -127,6 +127,7 @@ func (c *Client) ModelStatus(tags ...names.ModelTag) ([]base.ModelStatus, error)
 			Owner:              owner.Canonical(),
 			HostedMachineCount: r.HostedMachineCount,
 			ServiceCount:       r.ServiceCount,
+			VolumeCount:        r.VolumeCount,
 		}
 
 	}
@@ -51,9 +51,9 @@ func (s *uniterSuite) setUpTest(c *gc.C, addController bool) {
 		"db":        "internal",
 		"admin-api": "public",
 	}
-	_, err := s.State.AddSpace("internal", "", nil, false)
+	_, err := s.State.AddSpace("internal", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("public", "", nil, true)
+	_, err = s.State.AddSpace("public", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	// Create a machine, a service and add a unit so we can log in as
@@ -95,8 +95,8 @@ func (s *stateShim) ModelConfig() (*config.Config, error) {
 	return s.st.ModelConfig()
 }
 
-func (s *stateShim) AddSpace(name string, providerId network.Id, subnetIds []string, public bool) error {
-	_, err := s.st.AddSpace(name, providerId, subnetIds, public)
+func (s *stateShim) AddSpace(name string, providerId network.Id, subnetIds []string, public bool, labels map[string]string) error {
+	_, err := s.st.AddSpace(name, providerId, subnetIds, public, labels)
 	return err
 }
 
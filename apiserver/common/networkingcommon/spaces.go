@@ -78,7 +78,7 @@ func createOneSpace(backing NetworkBacking, args params.CreateSpaceParams) error
 	}
 
 	// Add the validated space.
-	err = backing.AddSpace(spaceTag.Id(), network.Id(args.ProviderId), subnets, args.Public)
+	err = backing.AddSpace(spaceTag.Id(), network.Id(args.ProviderId), subnets, args.Public, args.Labels)
 	if err != nil {
 		return errors.Trace(err)
 	}
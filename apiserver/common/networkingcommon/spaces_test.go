@@ -51,6 +51,7 @@ type checkCreateSpacesParams struct {
 	Error      string
 	Public     bool
 	ProviderId string
+	Labels     map[string]string
 }
 
 func (s *SpacesSuite) checkCreateSpaces(c *gc.C, p checkCreateSpacesParams) {
@@ -65,6 +66,7 @@ func (s *SpacesSuite) checkCreateSpaces(c *gc.C, p checkCreateSpacesParams) {
 	}
 	args.Public = p.Public
 	args.ProviderId = p.ProviderId
+	args.Labels = p.Labels
 
 	spaces := params.CreateSpacesParams{}
 	spaces.Spaces = append(spaces.Spaces, args)
@@ -85,7 +87,7 @@ func (s *SpacesSuite) checkCreateSpaces(c *gc.C, p checkCreateSpacesParams) {
 		apiservertesting.ZonedNetworkingEnvironCall("SupportsSpaces"),
 	}
 
-	addSpaceCalls := append(baseCalls, apiservertesting.BackingCall("AddSpace", p.Name, network.Id(p.ProviderId), p.Subnets, p.Public))
+	addSpaceCalls := append(baseCalls, apiservertesting.BackingCall("AddSpace", p.Name, network.Id(p.ProviderId), p.Subnets, p.Public, p.Labels))
 
 	if p.Error == "" {
 		apiservertesting.CheckMethodCalls(c, apiservertesting.SharedStub, addSpaceCalls...)
@@ -130,6 +132,15 @@ func (s *SpacesSuite) TestProviderId(c *gc.C) {
 	s.checkCreateSpaces(c, p)
 }
 
+func (s *SpacesSuite) TestLabels(c *gc.C) {
+	p := checkCreateSpacesParams{
+		Name:    "foo",
+		Subnets: []string{"10.0.0.0/24"},
+		Labels:  map[string]string{"team": "net"},
+	}
+	s.checkCreateSpaces(c, p)
+}
+
 func (s *SpacesSuite) TestEmptySpaceName(c *gc.C) {
 	p := checkCreateSpacesParams{
 		Subnets: []string{"10.0.0.0/24"},
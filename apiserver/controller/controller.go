@@ -356,6 +356,20 @@ func (c *ControllerAPI) environStatus(tag string) (params.ModelStatus, error) {
 		return status, errors.Trace(err)
 	}
 
+	volumes, err := st.AllVolumes()
+	if err != nil {
+		return status, errors.Trace(err)
+	}
+	var persistentVolumeCount int
+	for _, v := range volumes {
+		// Volumes which have not yet been provisioned
+		// have no provider resources to reclaim.
+		info, err := v.Info()
+		if err == nil && info.Persistent {
+			persistentVolumeCount++
+		}
+	}
+
 	env, err := st.Model()
 	if err != nil {
 		return status, errors.Trace(err)
@@ -370,6 +384,7 @@ func (c *ControllerAPI) environStatus(tag string) (params.ModelStatus, error) {
 		Life:               params.Life(env.Life().String()),
 		HostedMachineCount: len(hostedMachines),
 		ServiceCount:       len(services),
+		VolumeCount:        persistentVolumeCount,
 	}, nil
 }
 
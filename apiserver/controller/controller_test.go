@@ -268,6 +268,42 @@ func (s *controllerSuite) TestModelStatus(c *gc.C) {
 	}})
 }
 
+func (s *controllerSuite) TestModelStatusVolumeCount(c *gc.C) {
+	s.Factory.MakeMachine(c, &factory.MachineParams{
+		Volumes: []state.MachineVolumeParams{
+			{Volume: state.VolumeParams{Pool: "dummy", Size: 1024}},
+			{Volume: state.VolumeParams{Pool: "dummy", Size: 2048}},
+			{Volume: state.VolumeParams{Pool: "dummy", Size: 4096}},
+		},
+	})
+	volumes, err := s.State.AllVolumes()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(volumes, gc.HasLen, 3)
+
+	// Only persistent volumes are counted; the third
+	// volume is not provisioned, so it is not counted.
+	err = s.State.SetVolumeInfo(volumes[0].VolumeTag(), state.VolumeInfo{
+		VolumeId:   "vol-0",
+		Size:       1024,
+		Persistent: true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SetVolumeInfo(volumes[1].VolumeTag(), state.VolumeInfo{
+		VolumeId: "vol-1",
+		Size:     2048,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	req := params.Entities{
+		Entities: []params.Entity{{Tag: s.State.ModelTag().String()}},
+	}
+	results, err := s.controller.ModelStatus(req)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Check(results.Results[0].HostedMachineCount, gc.Equals, 1)
+	c.Check(results.Results[0].VolumeCount, gc.Equals, 1)
+}
+
 func (s *controllerSuite) TestInitiateModelMigration(c *gc.C) {
 	// Create two hosted models to migrate.
 	st1 := s.Factory.MakeModel(c, nil)
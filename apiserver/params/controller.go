@@ -39,6 +39,7 @@ type ModelStatus struct {
 	Life               Life   `json:"life"`
 	HostedMachineCount int    `json:"hosted-machine-count"`
 	ServiceCount       int    `json:"service-count"`
+	VolumeCount        int    `json:"volume-count"`
 	OwnerTag           string `json:"owner-tag"`
 }
 
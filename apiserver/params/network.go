@@ -555,10 +555,11 @@ type CreateSpacesParams struct {
 // CreateSpaceParams holds the space tag and at least one subnet
 // tag required to create a new space.
 type CreateSpaceParams struct {
-	SubnetTags []string `json:"SubnetTags"`
-	SpaceTag   string   `json:"SpaceTag"`
-	Public     bool     `json:"Public"`
-	ProviderId string   `json:"ProviderId,omitempty"`
+	SubnetTags []string          `json:"SubnetTags"`
+	SpaceTag   string            `json:"SpaceTag"`
+	Public     bool              `json:"Public"`
+	ProviderId string            `json:"ProviderId,omitempty"`
+	Labels     map[string]string `json:"Labels,omitempty"`
 }
 
 // ListSpacesResults holds the list of all available spaces.
@@ -181,9 +181,9 @@ func (s *withoutControllerSuite) TestProvisioningInfoWithSingleNegativeAndPositi
 
 func (s *withoutControllerSuite) addSpacesAndSubnets(c *gc.C) {
 	// Add a couple of spaces.
-	_, err := s.State.AddSpace("space1", "first space id", nil, true)
+	_, err := s.State.AddSpace("space1", "first space id", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("space2", "", nil, false) // no provider ID
+	_, err = s.State.AddSpace("space2", "", nil, false, nil) // no provider ID
 	c.Assert(err, jc.ErrorIsNil)
 	// Add 1 subnet into space1, and 2 into space2.
 	// Only the first subnet of space2 has AllocatableIPLow|High set.
@@ -251,7 +251,7 @@ func (s *withoutControllerSuite) TestProvisioningInfoWithEndpointBindings(c *gc.
 
 func (s *withoutControllerSuite) TestProvisioningInfoWithUnsuitableSpacesConstraints(c *gc.C) {
 	// Add an empty space.
-	_, err := s.State.AddSpace("empty", "", nil, true)
+	_, err := s.State.AddSpace("empty", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	consEmptySpace := constraints.MustParse("cpu-cores=123 mem=8G spaces=empty")
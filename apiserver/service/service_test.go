@@ -439,7 +439,7 @@ func (s *serviceSuite) testClientServicesDeployWithBindings(c *gc.C, endpointBin
 }
 
 func (s *serviceSuite) TestClientServicesDeployWithBindings(c *gc.C) {
-	s.State.AddSpace("a-space", "", nil, true)
+	s.State.AddSpace("a-space", "", nil, true, nil)
 	expected := map[string]string{
 		"endpoint": "a-space",
 		"ring":     "",
@@ -489,8 +489,8 @@ func (sb *StubBacking) AddSubnet(subnetInfo networkingcommon.BackingSubnetInfo)
 	return fs, nil
 }
 
-func (sb *StubBacking) AddSpace(name string, providerId network.Id, subnets []string, public bool) error {
-	sb.MethodCall(sb, "AddSpace", name, providerId, subnets, public)
+func (sb *StubBacking) AddSpace(name string, providerId network.Id, subnets []string, public bool, labels map[string]string) error {
+	sb.MethodCall(sb, "AddSpace", name, providerId, subnets, public, labels)
 	if err := sb.NextErr(); err != nil {
 		return err
 	}
@@ -2400,7 +2400,7 @@ func (s *uniterNetworkConfigSuite) SetUpTest(c *gc.C) {
 		SpaceName: "internal",
 	}}
 	for _, info := range subnetInfos {
-		_, err := s.base.State.AddSpace(info.SpaceName, "", nil, false)
+		_, err := s.base.State.AddSpace(info.SpaceName, "", nil, false, nil)
 		c.Assert(err, jc.ErrorIsNil)
 		_, err = s.base.State.AddSubnet(info)
 		c.Assert(err, jc.ErrorIsNil)
@@ -6,8 +6,13 @@ package controller
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"text/tabwriter"
 	"time"
@@ -15,16 +20,24 @@ import (
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	"github.com/juju/utils/set"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/api/backups"
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/api/controller"
+	apistorage "github.com/juju/juju/api/storage"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/block"
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju"
+	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/jujuclient"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/provider/registry"
 )
 
 // NewDestroyCommand returns a command to destroy a controller.
@@ -45,8 +58,71 @@ func NewDestroyCommand() cmd.Command {
 type destroyCommand struct {
 	destroyCommandBase
 	destroyModels bool
+	timeout       time.Duration
+	pollInterval  time.Duration
+	blockedFormat string
+	keepModelsArg string
+	keepModels    []string
+	migrationWait time.Duration
+	dryRun        bool
+	backupFile    string
+	progress      string
+	noWait        bool
+
+	// confirmThreshold is the number of hosted machines and services
+	// above which --destroy-all-models requires the controller name
+	// to be typed to confirm destruction.
+	confirmThreshold int
+
+	// force stops large controllers from requiring the controller
+	// name to be typed.
+	force bool
+
+	// ignoreBackupFailure lets destruction go ahead when the
+	// --backup cannot be made.
+	ignoreBackupFailure bool
+
+	// backupsapi is for mocking out the backups API in tests.
+	backupsapi destroyBackupsAPI
+
+	// newStorageAPI is for mocking out the storage API of each
+	// model in tests.
+	newStorageAPI func(modelUUID string) (destroyStorageAPI, error)
+
+	// listPersistentStorage, if set, is called instead of
+	// persistentStorage in tests.
+	listPersistentStorage func(api destroyControllerAPI) ([]string, error)
+
+	// hostedFootprint, if set, is called instead of newData to find
+	// the size of the hosted models in tests.
+	hostedFootprint func(api destroyControllerAPI, controllerUUID string) (ctrData, error)
 }
 
+// defaultDestroyTimeout is the default amount of time destroy-controller
+// will wait for hosted model resources to be reclaimed.
+const defaultDestroyTimeout = 30 * time.Minute
+
+// defaultPollInterval is the default interval between checks on the
+// reclamation of hosted model resources.
+const defaultPollInterval = 2 * time.Second
+
+// migrationPollInterval is how often destroy-controller checks whether
+// the models named with --keep-models have been migrated, when asked
+// to wait for them with --wait-for-migration.
+var migrationPollInterval = 5 * time.Second
+
+// defaultConfirmThreshold is the default number of hosted machines and
+// services above which --destroy-all-models asks for the controller
+// name to be typed, rather than a simple yes.
+const defaultConfirmThreshold = 10
+
+// progressFormats holds the formats accepted by --progress-format.
+var progressFormats = []string{"text", "json"}
+
+// stalledPollCount is the number of consecutive status checks without
+// any resources being reclaimed after which a warning is emitted.
+const stalledPollCount = 5
+
 // usageDetails has backticks which we want to keep for markdown processing.
 // TODO(cheryl): Do we want the usage, options, examples, and see also text in
 // backticks for markdown?
@@ -55,8 +131,70 @@ All models (initial model plus all workload/hosted) associated with the
 controller will first need to be destroyed, either in advance, or by
 specifying `[1:] + "`--destroy-all-models`." + `
 
+Models that must survive the controller can be named with --keep-models.
+Each of them must already have been migrated to another controller, or
+the command will refuse to destroy anything. To let migrations that are
+in progress finish, --wait-for-migration waits up to the given duration
+for the models to leave the controller before giving up. A name that
+matches none of the controller's models is taken to be a model that was
+already migrated, and a warning is printed in case it is mistyped.
+
+If the cloud resources of the controller cannot be destroyed once its
+hosted models have been reclaimed, the controller is kept, and running
+the command again resumes from that point. The confirmations, checks and
+backup that precede destruction are repeated if the controller can still
+be reached.
+
+With --no-wait, the command returns as soon as the controller has
+accepted the request to destroy it, without waiting for hosted model
+resources to be reclaimed or destroying the controller machines. Run the
+command again, without --no-wait, to complete the destruction.
+
+Persistent storage outlives the machines it is attached to, and is not
+reclaimed when the controller is destroyed. If any hosted model has
+persistent storage, it is listed, and you are asked again whether to
+continue, unless -y or --confirm was given.
+
+When --destroy-all-models would destroy more than --confirm-threshold
+hosted machines and services in total, a simple yes is not enough: the
+controller name must be typed at a second prompt, even if -y was given.
+Supplying the name with --confirm satisfies this, and --force skips it.
+
+The --dry-run option reports the controller and hosted models that
+would be destroyed, without destroying anything.
+
+The --backup option creates a backup of the controller and downloads it
+to the given local file before anything is destroyed. If the backup
+cannot be made, the command is aborted; pass --ignore-backup-failure as
+well to destroy the controller regardless.
+
+The --timeout option bounds the time spent waiting for hosted model
+resources to be reclaimed. It accepts a duration such as "90s" or "1h".
+How often the controller is checked while waiting can be changed with
+--poll-interval.
+
+While waiting, progress is reported on stderr. With --progress-format json
+it is instead written to stdout as one JSON object per line, holding the
+controller's status, the life of each hosted model and the remaining
+resource counts. The last line written has "complete" set to true.
+
+Instead of answering the interactive prompt, the controller name may be
+supplied with --confirm. The command is aborted if the name does not
+match the controller being destroyed. To script the answer to the prompt
+without using stdin, pass a file descriptor to read it from with
+--confirm-fd.
+
 Examples:
     juju destroy-controller --destroy-all-models mycontroller
+    juju destroy-controller --confirm mycontroller mycontroller
+    juju destroy-controller --destroy-all-models --timeout 10m mycontroller
+    juju destroy-controller --destroy-all-models --progress-format json mycontroller
+    juju destroy-controller --destroy-all-models --keep-models prod,staging mycontroller
+    juju destroy-controller --keep-models prod --wait-for-migration 30m mycontroller
+    juju destroy-controller --dry-run mycontroller
+    juju destroy-controller --destroy-all-models --no-wait mycontroller
+    juju destroy-controller --destroy-all-models --confirm-threshold 50 mycontroller
+    juju destroy-controller --backup ./mycontroller-backup.tar.gz mycontroller
 
 See also: 
     kill-controller`
@@ -70,6 +208,15 @@ This includes all machines, services, data and other resources.
 
 Continue [y/N]? `[1:]
 
+var destroyLargeMsg = `
+WARNING! The %q controller has %d hosted model(s) with %d machine(s)
+and %d service(s), which will all be destroyed.
+
+Type the controller name to continue: `[1:]
+
+var destroyStorageMsg = `
+Continue destroying the controller, leaving the storage above [y/N]? `[1:]
+
 // destroyControllerAPI defines the methods on the controller API endpoint
 // that the destroy command calls.
 type destroyControllerAPI interface {
@@ -81,6 +228,21 @@ type destroyControllerAPI interface {
 	AllModels() ([]base.UserModel, error)
 }
 
+// destroyBackupsAPI defines the methods on the backups API endpoint
+// that the destroy command calls when --backup is specified.
+type destroyBackupsAPI interface {
+	Close() error
+	Create(notes string) (*params.BackupsMetadataResult, error)
+	Download(id string) (io.ReadCloser, error)
+}
+
+// destroyStorageAPI defines the methods on the storage API endpoint of
+// a model that the destroy command calls to find persistent storage.
+type destroyStorageAPI interface {
+	Close() error
+	ListStorageDetails() ([]params.StorageDetails, error)
+}
+
 // destroyClientAPI defines the methods on the client API endpoint that the
 // destroy command might call.
 type destroyClientAPI interface {
@@ -102,11 +264,65 @@ func (c *destroyCommand) Info() *cmd.Info {
 // SetFlags implements Command.SetFlags.
 func (c *destroyCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.destroyModels, "destroy-all-models", false, "Destroy all hosted models in the controller")
+	f.DurationVar(&c.timeout, "timeout", defaultDestroyTimeout, "Maximum time to wait for hosted model resources to be reclaimed")
+	f.DurationVar(&c.pollInterval, "poll-interval", defaultPollInterval, "How often to check on hosted model resources while waiting for them to be reclaimed")
+	f.BoolVar(&c.dryRun, "dry-run", false, "Report what would be destroyed without destroying anything")
+	f.StringVar(&c.keepModelsArg, "keep-models", "", "Comma-separated names or UUIDs of models that must have been migrated off the controller")
+	f.DurationVar(&c.migrationWait, "wait-for-migration", 0, "Maximum time to wait for the --keep-models models to be migrated off the controller")
+	f.StringVar(&c.backupFile, "backup", "", "Back up the controller to this local file before destroying it")
+	f.BoolVar(&c.ignoreBackupFailure, "ignore-backup-failure", false, "Destroy the controller even if the --backup fails")
+	f.IntVar(&c.confirmThreshold, "confirm-threshold", defaultConfirmThreshold, "Number of hosted machines and services above which --destroy-all-models requires the controller name to be typed")
+	f.BoolVar(&c.force, "force", false, "Do not require the controller name to be typed when --destroy-all-models exceeds --confirm-threshold")
+	f.BoolVar(&c.noWait, "no-wait", false, "Return once destruction has been initiated, without waiting for it to complete")
+	f.StringVar(&c.progress, "progress-format", "text", "Format of the progress reported while waiting for hosted models: text|json")
+	f.StringVar(&c.blockedFormat, "output-format", "tabular", "Format of the blocked models list if destruction is blocked: tabular|json|yaml")
 	c.destroyCommandBase.SetFlags(f)
 }
 
+// Init implements Command.Init.
+func (c *destroyCommand) Init(args []string) error {
+	if c.timeout <= 0 {
+		return errors.Errorf("--timeout must be positive, got %v", c.timeout)
+	}
+	if c.pollInterval <= 0 {
+		return errors.Errorf("--poll-interval must be positive, got %v", c.pollInterval)
+	}
+	if c.ignoreBackupFailure && c.backupFile == "" {
+		return errors.New("--ignore-backup-failure can only be used with --backup")
+	}
+	if c.force && !c.destroyModels {
+		return errors.New("--force can only be used with --destroy-all-models")
+	}
+	if c.confirmThreshold < 0 {
+		return errors.Errorf("--confirm-threshold must not be negative, got %d", c.confirmThreshold)
+	}
+	if _, ok := blockedModelsFormatters[c.blockedFormat]; !ok {
+		return errors.Errorf("unknown output format %q", c.blockedFormat)
+	}
+	if !isProgressFormat(c.progress) {
+		return errors.Errorf("unknown progress format %q", c.progress)
+	}
+	if c.migrationWait < 0 {
+		return errors.Errorf("--wait-for-migration must not be negative, got %v", c.migrationWait)
+	}
+	if c.migrationWait > 0 && c.keepModelsArg == "" {
+		return errors.New("--wait-for-migration can only be used with --keep-models")
+	}
+	if c.keepModelsArg != "" {
+		for _, model := range strings.Split(c.keepModelsArg, ",") {
+			model = strings.TrimSpace(model)
+			if model == "" {
+				return errors.New("empty model name in --keep-models")
+			}
+			c.keepModels = append(c.keepModels, model)
+		}
+	}
+	return c.destroyCommandBase.Init(args)
+}
+
 // Run implements Command.Run
 func (c *destroyCommand) Run(ctx *cmd.Context) error {
+	defer c.closeAnswers()
 	controllerName := c.ControllerName()
 	store := c.ClientStore()
 	controllerDetails, err := store.ControllerByName(controllerName)
@@ -114,10 +330,13 @@ func (c *destroyCommand) Run(ctx *cmd.Context) error {
 		return errors.Annotate(err, "cannot read controller info")
 	}
 
-	if !c.assumeYes {
-		if err = confirmDestruction(ctx, c.ControllerName()); err != nil {
+	if !c.dryRun {
+		if err = c.confirm(ctx); err != nil {
 			return err
 		}
+		if shouldResumeDestroy(controllerName, controllerDetails.ControllerUUID) {
+			return c.resumeDestroy(ctx, store, controllerDetails.ControllerUUID)
+		}
 	}
 
 	// Attempt to connect to the API.  If we can't, fail the destroy.  Users will
@@ -128,6 +347,14 @@ func (c *destroyCommand) Run(ctx *cmd.Context) error {
 	}
 	defer api.Close()
 
+	if c.dryRun {
+		return c.reportDryRun(ctx, api, controllerDetails.ControllerUUID)
+	}
+
+	if err := c.checkBeforeDestroy(ctx, api, controllerDetails.ControllerUUID); err != nil {
+		return errors.Trace(err)
+	}
+
 	// Obtain controller environ so we can clean up afterwards.
 	controllerEnviron, err := c.getControllerEnviron(store, controllerName, api)
 	if err != nil {
@@ -165,31 +392,767 @@ func (c *destroyCommand) Run(ctx *cmd.Context) error {
 			}
 		}
 
+		if c.noWait {
+			ctx.Infof("Destruction of controller %q initiated", controllerName)
+			ctx.Infof("Run \"juju destroy-controller %s\" again to wait for it to complete", controllerName)
+			return nil
+		}
+
 		// Even if we've not just requested for hosted models to be destroyed,
 		// there may be some being destroyed already. We need to wait for them.
 		ctx.Infof("Waiting for hosted model resources to be reclaimed")
-		for ; hasUnDeadModels(modelsStatus); ctrStatus, modelsStatus = updateStatus(2 * time.Second) {
-			ctx.Infof(fmtCtrStatus(ctrStatus))
-			for _, model := range modelsStatus {
-				ctx.Verbosef(fmtModelStatus(model))
+		deadline := time.Now().Add(c.timeout)
+		prevStatus := ctrStatus
+		var unchangedPolls int
+		for polls := 0; hasUnDeadModels(modelsStatus); polls++ {
+			if c.progress == "json" {
+				if err := writeProgress(ctx, ctrStatus, modelsStatus, false); err != nil {
+					return errors.Trace(err)
+				}
+			} else {
+				ctx.Infof(fmtCtrStatus(ctrStatus))
+			}
+			if polls > 0 {
+				if c.progress != "json" {
+					if delta := fmtCtrStatusDelta(prevStatus, ctrStatus); delta != "" {
+						ctx.Infof(delta)
+					}
+				}
+				if sameResourceCounts(prevStatus, ctrStatus) {
+					unchangedPolls++
+				} else {
+					unchangedPolls = 0
+				}
+				if unchangedPolls == stalledPollCount {
+					ctx.Infof("WARNING: no resources reclaimed in the last %d checks, reclamation may be stalled", unchangedPolls)
+				}
+			}
+			prevStatus = ctrStatus
+			if c.progress != "json" {
+				for _, model := range modelsStatus {
+					ctx.Verbosef(fmtModelStatus(model))
+				}
+			}
+			remaining := deadline.Sub(time.Now())
+			if remaining <= 0 {
+				return c.timedOutError(modelsStatus)
+			}
+			wait := c.pollInterval
+			if remaining < wait {
+				wait = remaining
+			}
+			ctrStatus, modelsStatus = updateStatus(wait)
+		}
+		if c.progress == "json" {
+			if err := writeProgress(ctx, ctrStatus, modelsStatus, true); err != nil {
+				return errors.Trace(err)
 			}
 		}
 		ctx.Infof("All hosted models reclaimed, cleaning up controller machines")
-		return environs.Destroy(c.ControllerName(), controllerEnviron, store)
+		return c.destroyEnviron(ctx, controllerEnviron, store, controllerDetails.ControllerUUID)
+	}
+}
+
+func isProgressFormat(format string) bool {
+	for _, f := range progressFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// destroyProgress is written to stdout once per status check when
+// --progress-format json is specified.
+type destroyProgress struct {
+	Controller destroyProgressController `json:"controller"`
+	Models     []destroyProgressModel    `json:"models"`
+	Complete   bool                      `json:"complete"`
+}
+
+type destroyProgressController struct {
+	UUID               string      `json:"uuid"`
+	Life               params.Life `json:"life"`
+	HostedModelCount   int         `json:"hosted-model-count"`
+	HostedMachineCount int         `json:"hosted-machine-count"`
+	ServiceCount       int         `json:"service-count"`
+	VolumeCount        int         `json:"volume-count"`
+}
+
+type destroyProgressModel struct {
+	UUID         string      `json:"uuid"`
+	Owner        string      `json:"owner"`
+	Name         string      `json:"name"`
+	Life         params.Life `json:"life"`
+	MachineCount int         `json:"machine-count"`
+	ServiceCount int         `json:"service-count"`
+	VolumeCount  int         `json:"volume-count"`
+}
+
+// writeProgress writes the given controller and hosted model status to
+// stdout as a single line of JSON.
+func writeProgress(ctx *cmd.Context, ctrStatus ctrData, modelsStatus []modelData, complete bool) error {
+	progress := destroyProgress{
+		Controller: destroyProgressController{
+			UUID:               ctrStatus.UUID,
+			Life:               ctrStatus.Life,
+			HostedModelCount:   ctrStatus.HostedModelCount,
+			HostedMachineCount: ctrStatus.HostedMachineCount,
+			ServiceCount:       ctrStatus.ServiceCount,
+			VolumeCount:        ctrStatus.VolumeCount,
+		},
+		Models:   make([]destroyProgressModel, len(modelsStatus)),
+		Complete: complete,
+	}
+	for i, model := range modelsStatus {
+		progress.Models[i] = destroyProgressModel{
+			UUID:         model.UUID,
+			Owner:        model.Owner,
+			Name:         model.Name,
+			Life:         model.Life,
+			MachineCount: model.HostedMachineCount,
+			ServiceCount: model.ServiceCount,
+			VolumeCount:  model.VolumeCount,
+		}
+	}
+	return json.NewEncoder(ctx.Stdout).Encode(progress)
+}
+
+// checkBeforeDestroy asks for any confirmation needed for a large
+// controller or persistent storage, ensures the models named with
+// --keep-models have left the controller, and backs the controller up
+// if --backup was specified. Nothing has been destroyed if it returns
+// an error.
+func (c *destroyCommand) checkBeforeDestroy(ctx *cmd.Context, api destroyControllerAPI, controllerUUID string) error {
+	if err := c.confirmLargeController(ctx, api, controllerUUID); err != nil {
+		return errors.Trace(err)
+	}
+	if err := c.waitForKeptModelsMigrated(ctx, api); err != nil {
+		return errors.Trace(err)
+	}
+	if err := c.checkPersistentStorage(ctx, api); err != nil {
+		return errors.Trace(err)
+	}
+	if c.backupFile != "" {
+		if err := c.backup(ctx); err != nil {
+			return c.backupFailed(ctx, err)
+		}
+	}
+	return nil
+}
+
+// backupFailed returns an error aborting the destruction because the
+// controller could not be backed up, unless --ignore-backup-failure
+// was specified.
+func (c *destroyCommand) backupFailed(ctx *cmd.Context, err error) error {
+	if !c.ignoreBackupFailure {
+		return errors.Annotate(err, "cannot back up controller, aborting destruction")
+	}
+	ctx.Infof("WARNING: cannot back up controller: %v", err)
+	ctx.Infof("Destroying the controller anyway, as --ignore-backup-failure was specified")
+	return nil
+}
+
+// destroyResumePath returns the path of the file recording that the
+// hosted models of the named controller have been reclaimed, and its
+// cloud resources are being destroyed. The file holds the controller's
+// UUID, so that a record left for an earlier controller of the same
+// name is not mistaken for one for the current controller.
+func destroyResumePath(controllerName string) string {
+	return osenv.JujuXDGDataHomePath("destroying", controllerName)
+}
+
+// shouldResumeDestroy reports whether an earlier run of the command
+// reclaimed the hosted models of the controller with the given name and
+// UUID, but failed to destroy its cloud resources.
+func shouldResumeDestroy(controllerName, controllerUUID string) bool {
+	recorded, err := ioutil.ReadFile(destroyResumePath(controllerName))
+	if err != nil {
+		return false
+	}
+	return string(recorded) == controllerUUID
+}
+
+// destroyEnviron destroys the cloud resources of the controller and
+// then removes the controller from the client store. If destroying the
+// cloud resources fails, the controller is kept in the store and a
+// record is left so that running the command again resumes from this
+// point, even if the controller's API server is no longer reachable.
+func (c *destroyCommand) destroyEnviron(ctx *cmd.Context, env environs.Environ, store jujuclient.ClientStore, controllerUUID string) error {
+	controllerName := c.ControllerName()
+	resumePath := destroyResumePath(controllerName)
+	if err := os.MkdirAll(filepath.Dir(resumePath), 0700); err != nil {
+		return errors.Annotate(err, "cannot record destroy progress")
+	}
+	if err := ioutil.WriteFile(resumePath, []byte(controllerUUID), 0600); err != nil {
+		return errors.Annotate(err, "cannot record destroy progress")
+	}
+
+	snapshot := snapshotResources(env)
+	if err := env.Destroy(); err != nil {
+		return errors.Errorf(`cannot destroy cloud resources for controller %q: %v
+
+All hosted models have been destroyed, and the controller has been
+kept in the client store. Run this command again to resume destroying
+the controller's cloud resources.`, controllerName, err)
+	}
+	reportReclaimed(ctx, env, snapshot)
+
+	if err := store.RemoveController(controllerName); err != nil && !errors.IsNotFound(err) {
+		return errors.Annotatef(err, "controller %q destroyed, but cannot remove it from the client store", controllerName)
+	}
+	removeDestroyResumeRecord(controllerName)
+	return nil
+}
+
+// removeDestroyResumeRecord removes the record left by destroyEnviron
+// for the named controller, if there is one.
+func removeDestroyResumeRecord(controllerName string) {
+	if err := os.Remove(destroyResumePath(controllerName)); err != nil && !os.IsNotExist(err) {
+		logger.Warningf("cannot remove destroy progress record: %v", err)
+	}
+}
+
+// resumeDestroy resumes destruction of a controller whose hosted models
+// were reclaimed by an earlier run that failed to destroy its cloud
+// resources. The checks made before destroying anything are run again
+// if the API server can still be reached; if any of them fails, the
+// resume record is removed, so the next run starts from the beginning.
+// Otherwise they are known to have passed, as the record is only
+// written once they have.
+func (c *destroyCommand) resumeDestroy(ctx *cmd.Context, store jujuclient.ClientStore, controllerUUID string) error {
+	ctx.Infof("Resuming destruction of controller %q, hosted models were reclaimed by a previous run", c.ControllerName())
+	api, err := c.getControllerAPI()
+	if err == nil {
+		defer api.Close()
+		if err := c.checkBeforeDestroy(ctx, api, controllerUUID); err != nil {
+			removeDestroyResumeRecord(c.ControllerName())
+			return errors.Trace(err)
+		}
+	} else {
+		logger.Debugf("cannot connect to API, skipping checks made by the previous run: %v", err)
+		api = nil
+		if c.backupFile != "" {
+			if err := c.backupFailed(ctx, errors.Annotate(err, "cannot connect to API")); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	controllerEnviron, err := c.getControllerEnviron(store, c.ControllerName(), api)
+	if err != nil {
+		return errors.Annotate(err, "getting controller environ")
+	}
+	return c.destroyEnviron(ctx, controllerEnviron, store, controllerUUID)
+}
+
+// allInstanceIds returns the IDs of all instances known to env.
+func allInstanceIds(env environs.Environ) ([]instance.Id, error) {
+	instances, err := env.AllInstances()
+	if err == environs.ErrNoInstances {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ids := make([]instance.Id, len(instances))
+	for i, inst := range instances {
+		ids[i] = inst.Id()
+	}
+	return ids, nil
+}
+
+// resourceSnapshot records the provider resources of an environ
+// before it is destroyed, so that reportReclaimed can tell which of
+// them were removed.
+type resourceSnapshot struct {
+	instances   []instance.Id
+	volumes     []listedStorage
+	filesystems []listedStorage
+}
+
+// listedStorage holds the IDs of the volumes or filesystems listed by
+// one storage source, and the function that listed them.
+type listedStorage struct {
+	ids  []string
+	list func() ([]string, error)
+}
+
+// filesystemLister is implemented by filesystem sources that can list
+// the filesystems they manage. storage.FilesystemSource has no such
+// method, so filesystems are only reported for sources that have one.
+type filesystemLister interface {
+	ListFilesystems() ([]string, error)
+}
+
+// snapshotResources records the instances of env, and the volumes and
+// filesystems of its dynamic environ-scoped storage sources; storage
+// of other scopes goes with the instances. Anything that cannot be
+// listed is left out of the snapshot, as it can't be reported on.
+func snapshotResources(env environs.Environ) resourceSnapshot {
+	var snapshot resourceSnapshot
+	instanceIds, err := allInstanceIds(env)
+	if err != nil {
+		logger.Debugf("cannot list controller instances: %v", err)
+	}
+	snapshot.instances = instanceIds
+
+	environConfig := env.Config()
+	providerTypes, _ := registry.EnvironStorageProviders(environConfig.Type())
+	for _, providerType := range providerTypes {
+		provider, err := registry.StorageProvider(providerType)
+		if err != nil {
+			logger.Debugf("cannot get storage provider %q: %v", providerType, err)
+			continue
+		}
+		if !provider.Dynamic() || provider.Scope() != storage.ScopeEnviron {
+			continue
+		}
+		sourceConfig, err := storage.NewConfig(string(providerType), providerType, map[string]interface{}{})
+		if err != nil {
+			logger.Debugf("cannot configure storage provider %q: %v", providerType, err)
+			continue
+		}
+		if provider.Supports(storage.StorageKindBlock) {
+			source, err := provider.VolumeSource(environConfig, sourceConfig)
+			if err != nil {
+				logger.Debugf("cannot get %q volume source: %v", providerType, err)
+			} else if listed, ok := listStorage(source.ListVolumes); ok {
+				snapshot.volumes = append(snapshot.volumes, listed)
+			}
+		}
+		if provider.Supports(storage.StorageKindFilesystem) {
+			source, err := provider.FilesystemSource(environConfig, sourceConfig)
+			if err != nil {
+				logger.Debugf("cannot get %q filesystem source: %v", providerType, err)
+			} else if lister, ok := source.(filesystemLister); ok {
+				if listed, ok := listStorage(lister.ListFilesystems); ok {
+					snapshot.filesystems = append(snapshot.filesystems, listed)
+				}
+			}
+		}
+	}
+	return snapshot
+}
+
+// listStorage calls list, returning the IDs it lists along with list
+// itself, so they can be listed again once the environ is destroyed.
+func listStorage(list func() ([]string, error)) (listedStorage, bool) {
+	ids, err := list()
+	if err != nil {
+		logger.Debugf("cannot list storage: %v", err)
+		return listedStorage{}, false
+	}
+	return listedStorage{ids: ids, list: list}, true
+}
+
+// reportReclaimed writes a summary of the resources in snapshot that
+// were removed when env was destroyed, followed by a warning listing
+// any the provider could not confirm were removed.
+func reportReclaimed(ctx *cmd.Context, env environs.Environ, snapshot resourceSnapshot) {
+	var warnings []string
+	report := func(verb, kind string, removed, remaining []string) {
+		if len(removed) > 0 {
+			ctx.Infof("%s %d %s(s): %s", verb, len(removed), kind, strings.Join(removed, ", "))
+		}
+		if len(remaining) > 0 {
+			warnings = append(warnings, fmt.Sprintf("  %ss: %s", kind, strings.Join(remaining, ", ")))
+		}
+	}
+	terminated, remaining := reclaimedInstances(env, snapshot.instances)
+	report("Terminated", "instance", terminated, remaining)
+	removed, remaining := reclaimedStorage(snapshot.volumes)
+	report("Removed", "volume", removed, remaining)
+	removed, remaining = reclaimedStorage(snapshot.filesystems)
+	report("Removed", "filesystem", removed, remaining)
+	if len(warnings) > 0 {
+		ctx.Infof(`WARNING: the provider could not confirm that the following
+resources were removed:
+%s
+
+Review your cloud provider console for any resources that
+need to be cleaned up.`, strings.Join(warnings, "\n"))
+	}
+}
+
+// reclaimedInstances splits ids into the instances that env no
+// longer has and those it still has, or may still have.
+func reclaimedInstances(env environs.Environ, ids []instance.Id) (terminated, remaining []string) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	instances, err := env.Instances(ids)
+	unconfirmed := false
+	switch err {
+	case nil, environs.ErrPartialInstances:
+	case environs.ErrNoInstances:
+		instances = nil
+	default:
+		// We can't tell which instances remain, so
+		// none of them are confirmed as terminated.
+		logger.Debugf("cannot check for remaining instances: %v", err)
+		unconfirmed = true
+	}
+	for i, id := range ids {
+		if unconfirmed || (i < len(instances) && instances[i] != nil) {
+			remaining = append(remaining, string(id))
+		} else {
+			terminated = append(terminated, string(id))
+		}
+	}
+	return terminated, remaining
+}
+
+// reclaimedStorage lists each storage source in listed again, and
+// splits the IDs listed before into those no longer listed and those
+// still listed, or that may still be.
+func reclaimedStorage(listed []listedStorage) (removed, remaining []string) {
+	for _, l := range listed {
+		if len(l.ids) == 0 {
+			continue
+		}
+		ids, err := l.list()
+		if err != nil {
+			logger.Debugf("cannot check for remaining storage: %v", err)
+			remaining = append(remaining, l.ids...)
+			continue
+		}
+		still := set.NewStrings(ids...)
+		for _, id := range l.ids {
+			if still.Contains(id) {
+				remaining = append(remaining, id)
+			} else {
+				removed = append(removed, id)
+			}
+		}
+	}
+	return removed, remaining
+}
+
+// reportDryRun writes a report of the controller and hosted models
+// that would be destroyed to stdout.
+func (c *destroyCommand) reportDryRun(ctx *cmd.Context, api destroyControllerAPI, controllerUUID string) error {
+	ctrStatus, modelsStatus, err := newData(api, controllerUUID)
+	if err != nil {
+		return errors.Annotate(err, "cannot get controller status")
+	}
+	fmt.Fprintf(ctx.Stdout, "Controller %q (%s) is %s\n", c.ControllerName(), controllerUUID, ctrStatus.Life)
+	fmt.Fprintf(ctx.Stdout, "Would destroy %s\n", fmtCtrResources(ctrStatus))
+	if len(modelsStatus) > 0 {
+		fmt.Fprintln(ctx.Stdout, "Models:")
+		for _, model := range modelsStatus {
+			fmt.Fprintln(ctx.Stdout, fmtModelStatus(model))
+		}
+	}
+	if hasAliveModels(modelsStatus) && !c.destroyModels {
+		fmt.Fprintln(ctx.Stdout, "The controller has live hosted models; --destroy-all-models is required to destroy them.")
+	}
+	return nil
+}
+
+// confirmLargeController asks for the controller name to be typed when
+// --destroy-all-models would destroy more than --confirm-threshold
+// hosted machines and services, unless it was already supplied with
+// --confirm or --force was given.
+func (c *destroyCommand) confirmLargeController(ctx *cmd.Context, api destroyControllerAPI, controllerUUID string) error {
+	if !c.destroyModels || c.force || c.confirmName != "" {
+		return nil
 	}
+	getFootprint := func(api destroyControllerAPI, controllerUUID string) (ctrData, error) {
+		ctrStatus, _, err := newData(api, controllerUUID)
+		return ctrStatus, err
+	}
+	if c.hostedFootprint != nil {
+		getFootprint = c.hostedFootprint
+	}
+	ctrStatus, err := getFootprint(api, controllerUUID)
+	if err != nil {
+		ctx.Infof("WARNING: cannot determine the size of the hosted models: %v", err)
+		return nil
+	}
+	if ctrStatus.HostedMachineCount+ctrStatus.ServiceCount <= c.confirmThreshold {
+		return nil
+	}
+	fmt.Fprintf(ctx.Stdout, destroyLargeMsg,
+		c.ControllerName(), ctrStatus.HostedModelCount,
+		ctrStatus.HostedMachineCount, ctrStatus.ServiceCount,
+	)
+	answers, err := c.answers(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	answers.Scan()
+	if err := answers.Err(); err != nil {
+		return errors.Annotate(err, "controller destruction aborted")
+	}
+	if answer := strings.TrimSpace(answers.Text()); answer != c.ControllerName() {
+		return errors.Errorf(
+			"controller destruction aborted: %q does not match controller name %q",
+			answer, c.ControllerName(),
+		)
+	}
+	return nil
+}
+
+// getBackupsAPI returns a backups API client connected to the
+// controller model.
+func (c *destroyCommand) getBackupsAPI() (destroyBackupsAPI, error) {
+	if c.backupsapi != nil {
+		return c.backupsapi, nil
+	}
+	root, err := c.JujuCommandBase.NewAPIRoot(
+		c.ClientStore(), c.ControllerName(), c.AccountName(), environs.ControllerModelName,
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	client, err := backups.NewClient(root)
+	if err != nil {
+		root.Close()
+		return nil, errors.Trace(err)
+	}
+	return client, nil
+}
+
+// getStorageAPI returns a storage API client for the model with the
+// given UUID.
+func (c *destroyCommand) getStorageAPI(modelUUID string) (destroyStorageAPI, error) {
+	if c.newStorageAPI != nil {
+		return c.newStorageAPI(modelUUID)
+	}
+	connParams, err := c.JujuCommandBase.NewAPIConnectionParams(
+		c.ClientStore(), c.ControllerName(), c.AccountName(), "",
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	connParams.ModelUUID = modelUUID
+	root, err := juju.NewAPIConnection(connParams)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return apistorage.NewClient(root), nil
+}
+
+// persistentStorage returns a description of each persistent storage
+// instance in the controller's models, in the form
+// "<model>: <storage id> (<kind>)".
+func (c *destroyCommand) persistentStorage(api destroyControllerAPI) ([]string, error) {
+	models, err := api.AllModels()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var result []string
+	for _, model := range models {
+		storageAPI, err := c.getStorageAPI(model.UUID)
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot connect to model %q", model.Name)
+		}
+		details, err := storageAPI.ListStorageDetails()
+		storageAPI.Close()
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot list storage in model %q", model.Name)
+		}
+		for _, one := range details {
+			if !one.Persistent {
+				continue
+			}
+			id := one.StorageTag
+			if tag, err := names.ParseStorageTag(one.StorageTag); err == nil {
+				id = tag.Id()
+			}
+			result = append(result, fmt.Sprintf("%s: %s (%s)", model.Name, id, one.Kind.String()))
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// checkPersistentStorage warns about any persistent storage that will
+// be left behind when the controller is destroyed. Unless -y or
+// --confirm was given, the user is then asked whether to continue.
+func (c *destroyCommand) checkPersistentStorage(ctx *cmd.Context, api destroyControllerAPI) error {
+	listPersistentStorage := c.persistentStorage
+	if c.listPersistentStorage != nil {
+		listPersistentStorage = c.listPersistentStorage
+	}
+	storage, err := listPersistentStorage(api)
+	if err != nil {
+		ctx.Infof("WARNING: cannot check for persistent storage: %v", err)
+		return nil
+	}
+	if len(storage) == 0 {
+		return nil
+	}
+	ctx.Infof(`WARNING: the following persistent storage will not be destroyed
+with the controller, and will remain in the cloud until removed:
+	%s`, strings.Join(storage, "\n\t"))
+	if c.assumeYes || c.confirmName != "" {
+		return nil
+	}
+	answers, err := c.answers(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	fmt.Fprint(ctx.Stdout, destroyStorageMsg)
+	return readConfirmation(answers)
+}
+
+// backup creates a backup of the controller and downloads the
+// archive to the file named with --backup.
+func (c *destroyCommand) backup(ctx *cmd.Context) (err error) {
+	filename := ctx.AbsPath(c.backupFile)
+	ctx.Infof("Backing up controller to %s", filename)
+	client, err := c.getBackupsAPI()
+	if err != nil {
+		return errors.Annotate(err, "cannot connect to API")
+	}
+	defer client.Close()
+
+	notes := fmt.Sprintf("backup of controller %q before destruction", c.ControllerName())
+	result, err := client.Create(notes)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	archive, err := client.Download(result.ID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer archive.Close()
+
+	outfile, err := os.Create(filename)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer func() {
+		if closeErr := outfile.Close(); err == nil {
+			err = errors.Trace(closeErr)
+		}
+		if err != nil {
+			// Don't leave a partial archive that could be
+			// mistaken for a usable backup.
+			os.Remove(filename)
+		}
+	}()
+	if _, err := io.Copy(outfile, archive); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// waitForKeptModelsMigrated ensures that every model named with
+// --keep-models is no longer hosted by the controller, waiting up to
+// the --wait-for-migration duration for them to be migrated away. A
+// model is considered to have been migrated away if the controller no
+// longer knows about it, or if it is Dead. As a mistyped name looks just
+// like a model that was migrated away, names that match no model when
+// first checked are reported.
+func (c *destroyCommand) waitForKeptModelsMigrated(ctx *cmd.Context, api destroyControllerAPI) error {
+	if len(c.keepModels) == 0 {
+		return nil
+	}
+	deadline := time.Now().Add(c.migrationWait)
+	for checked := false; ; checked = true {
+		local, unknown, err := c.keptModelsNotMigrated(api)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !checked {
+			for _, keep := range unknown {
+				ctx.Infof("WARNING: the controller has no model %q; assuming it was migrated away, check the name given with --keep-models if not", keep)
+			}
+		}
+		if len(local) == 0 {
+			return nil
+		}
+		remaining := deadline.Sub(time.Now())
+		if remaining <= 0 {
+			return errors.Errorf(`cannot destroy controller %q
+
+The following models were requested to be kept, but have not
+been migrated off the controller:
+	%s
+
+Migrate these models to another controller, then run this
+command again.`, c.ControllerName(), strings.Join(local, "\n\t"))
+		}
+		ctx.Infof("Waiting for %d model(s) to be migrated off the controller", len(local))
+		wait := migrationPollInterval
+		if remaining < wait {
+			wait = remaining
+		}
+		time.Sleep(wait)
+	}
+}
+
+// keptModelsNotMigrated returns a description of each model named with
+// --keep-models that is still hosted by the controller, and the names
+// given with --keep-models that match none of the controller's models.
+func (c *destroyCommand) keptModelsNotMigrated(api destroyControllerAPI) (local, unknown []string, _ error) {
+	models, err := api.AllModels()
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot list models")
+	}
+	var tags []names.ModelTag
+	known := set.NewStrings()
+	for _, model := range models {
+		if c.isKeptModel(model.Name, model.UUID) {
+			tags = append(tags, names.NewModelTag(model.UUID))
+			known.Add(model.Name)
+			known.Add(model.UUID)
+		}
+	}
+	for _, keep := range c.keepModels {
+		if !known.Contains(keep) {
+			unknown = append(unknown, keep)
+		}
+	}
+	if len(tags) == 0 {
+		return nil, unknown, nil
+	}
+	status, err := api.ModelStatus(tags...)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot get model status")
+	}
+	for _, model := range models {
+		if !c.isKeptModel(model.Name, model.UUID) {
+			continue
+		}
+		for _, s := range status {
+			if s.UUID == model.UUID && s.Life != params.Dead {
+				local = append(local, fmt.Sprintf("%s (%s)", model.Name, model.UUID))
+			}
+		}
+	}
+	return local, unknown, nil
+}
+
+// isKeptModel reports whether the model with the given name or UUID
+// was named with --keep-models.
+func (c *destroyCommand) isKeptModel(name, uuid string) bool {
+	for _, keep := range c.keepModels {
+		if keep == name || keep == uuid {
+			return true
+		}
+	}
+	return false
 }
 
 // checkNoAliveHostedModels ensures that the given set of hosted models
-// contains none that are Alive. If there are, an message is printed
-// out to
+// contains none that are Alive, ignoring any models named with
+// --keep-models. If there are, an message is printed out to
 func (c *destroyCommand) checkNoAliveHostedModels(ctx *cmd.Context, models []modelData) error {
-	if !hasAliveModels(models) {
+	var alive []modelData
+	for _, model := range models {
+		if !c.isKeptModel(model.Name, model.UUID) {
+			alive = append(alive, model)
+		}
+	}
+	if !hasAliveModels(alive) {
 		return nil
 	}
 	// The user did not specify --destroy-all-models,
 	// and there are models still alive.
 	var buf bytes.Buffer
-	for _, model := range models {
+	for _, model := range alive {
 		if model.Life != params.Alive {
 			continue
 		}
@@ -207,6 +1170,28 @@ Models:
 %s`, c.ControllerName(), buf.String())
 }
 
+// timedOutError returns the error reported when hosted model resources
+// were not reclaimed within the configured timeout.
+func (c *destroyCommand) timedOutError(models []modelData) error {
+	var buf bytes.Buffer
+	for _, model := range models {
+		if model.Life == params.Dead {
+			continue
+		}
+		buf.WriteString(fmtModelStatus(model))
+		buf.WriteRune('\n')
+	}
+	return errors.Errorf(`timed out after %v waiting for hosted models to be reclaimed
+
+The following models have not been reclaimed:
+%s
+If the controller is unusable, then you may run
+
+    juju kill-controller
+
+to forcibly destroy the controller.`, c.timeout, buf.String())
+}
+
 // ensureUserFriendlyErrorLog ensures that error will be logged and displayed
 // in a user-friendly manner with readable and digestable error message.
 func (c *destroyCommand) ensureUserFriendlyErrorLog(destroyErr error, ctx *cmd.Context, api destroyControllerAPI) error {
@@ -217,25 +1202,102 @@ func (c *destroyCommand) ensureUserFriendlyErrorLog(destroyErr error, ctx *cmd.C
 		logger.Errorf(destroyControllerBlockedMsg)
 		if api != nil {
 			models, err := api.ListBlockedModels()
-			var bytes []byte
 			if err == nil {
-				bytes, err = formatTabularBlockedModels(models)
+				err = c.writeBlockedModels(ctx, models)
 			}
 			if err != nil {
 				logger.Errorf("Unable to list blocked models: %s", err)
 				return cmd.ErrSilent
 			}
-			ctx.Infof(string(bytes))
 		}
 		return cmd.ErrSilent
 	}
 	if params.IsCodeHasHostedModels(destroyErr) {
 		return destroyErr
 	}
+	if isAuthError(destroyErr) {
+		logger.Errorf(authFailureMsg, c.ControllerName())
+		return destroyErr
+	}
 	logger.Errorf(stdFailureMsg, c.ControllerName())
 	return destroyErr
 }
 
+// isAuthError returns whether err means that the controller was reached,
+// but refused the credentials or the permissions of the user.
+func isAuthError(err error) bool {
+	if errors.IsUnauthorized(errors.Cause(err)) {
+		return true
+	}
+	switch params.ErrCode(err) {
+	case params.CodeUnauthorized, params.CodeForbidden, params.CodeDischargeRequired:
+		return true
+	}
+	return false
+}
+
+// writeBlockedModels writes the given blocked models in the format
+// requested with --output-format. The tabular format is written to
+// stderr alongside the other progress messages, while the structured
+// formats are written to stdout so they may be consumed by scripts.
+func (c *destroyCommand) writeBlockedModels(ctx *cmd.Context, models []params.ModelBlockInfo) error {
+	format := c.blockedFormat
+	if format == "" {
+		format = "tabular"
+	}
+	if format == "tabular" {
+		bytes, err := formatTabularBlockedModels(models)
+		if err != nil {
+			return err
+		}
+		ctx.Infof(string(bytes))
+		return nil
+	}
+	bytes, err := blockedModelsFormatters[format](toBlockedModels(models))
+	if err != nil {
+		return err
+	}
+	if len(bytes) > 0 && bytes[len(bytes)-1] != '\n' {
+		bytes = append(bytes, '\n')
+	}
+	_, err = ctx.Stdout.Write(bytes)
+	return err
+}
+
+// blockedModelsFormatters holds the formatters that may be used to
+// report models blocking controller destruction.
+var blockedModelsFormatters = map[string]cmd.Formatter{
+	"tabular": formatTabularBlockedModels,
+	"json":    cmd.FormatJson,
+	"yaml":    cmd.FormatYaml,
+}
+
+// blockedModel holds the structured representation of a model
+// blocking controller destruction.
+type blockedModel struct {
+	Name   string   `json:"name" yaml:"name"`
+	UUID   string   `json:"model-uuid" yaml:"model-uuid"`
+	Owner  string   `json:"owner" yaml:"owner"`
+	Blocks []string `json:"blocks" yaml:"blocks"`
+}
+
+func toBlockedModels(models []params.ModelBlockInfo) []blockedModel {
+	result := make([]blockedModel, len(models))
+	for i, model := range models {
+		blocks := make([]string, len(model.Blocks))
+		for j, blk := range model.Blocks {
+			blocks[j] = block.OperationFromType(blk)
+		}
+		result[i] = blockedModel{
+			Name:   model.Name,
+			UUID:   model.UUID,
+			Owner:  model.OwnerTag,
+			Blocks: blocks,
+		}
+	}
+	return result
+}
+
 const destroyControllerBlockedMsg = `there are blocks preventing controller destruction
 To remove all blocks in the controller, please run:
 
@@ -243,6 +1305,18 @@ To remove all blocks in the controller, please run:
 
 `
 
+const authFailureMsg = `failed to destroy controller %q
+
+The controller could be reached, but refused the credentials or the
+permissions of the current user. Check that you are logged in to the
+controller as a user allowed to destroy it, for example with
+
+    juju login
+
+and try again.
+
+`
+
 // TODO(axw) this should only be printed out if we couldn't
 // connect to the controller.
 const stdFailureMsg = `failed to destroy controller %q
@@ -296,7 +1370,22 @@ func blocksToStr(blocks []string) string {
 // destroy and controller kill commands require.
 type destroyCommandBase struct {
 	modelcmd.ControllerCommandBase
-	assumeYes bool
+	assumeYes   bool
+	confirmName string
+	confirmFD   int
+
+	// answerScanner reads the answers to confirmation prompts. It is
+	// shared by all the prompts, so that no answer is lost to another
+	// prompt's buffering.
+	answerScanner *bufio.Scanner
+
+	// answerFile is the file opened on --confirm-fd, if any. It is
+	// closed by closeAnswers when the command finishes.
+	answerFile *os.File
+
+	// controllerEnviron caches the result of getControllerEnviron,
+	// so the bootstrap config is only looked up once per run.
+	controllerEnviron environs.Environ
 
 	// The following fields are for mocking out
 	// api behavior for testing.
@@ -320,6 +1409,8 @@ func (c *destroyCommandBase) getControllerAPI() (destroyControllerAPI, error) {
 func (c *destroyCommandBase) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.assumeYes, "y", false, "Do not ask for confirmation")
 	f.BoolVar(&c.assumeYes, "yes", false, "")
+	f.StringVar(&c.confirmName, "confirm", "", "Confirm destruction by supplying the controller name")
+	f.IntVar(&c.confirmFD, "confirm-fd", -1, "Read the answer to the confirmation prompt from this file descriptor instead of stdin")
 }
 
 // Init implements Command.Init.
@@ -338,10 +1429,14 @@ func (c *destroyCommandBase) Init(args []string) error {
 //
 // getControllerEnviron gets the information required to get the
 // Environ by first checking the config store, then querying the
-// API if the information is not in the store.
+// API if the information is not in the store. The Environ is cached,
+// so subsequent calls do not repeat the lookup.
 func (c *destroyCommandBase) getControllerEnviron(
 	store jujuclient.ClientStore, controllerName string, sysAPI destroyControllerAPI,
 ) (_ environs.Environ, err error) {
+	if c.controllerEnviron != nil {
+		return c.controllerEnviron, nil
+	}
 	cfg, err := modelcmd.NewGetBootstrapConfigFunc(store)(controllerName)
 	if errors.IsNotFound(err) {
 		if sysAPI == nil {
@@ -360,14 +1455,84 @@ func (c *destroyCommandBase) getControllerEnviron(
 	} else if err != nil {
 		return nil, errors.Annotate(err, "getting bootstrap config from client store")
 	}
-	return environs.New(cfg)
+	env, err := environs.New(cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	c.controllerEnviron = env
+	return env, nil
+}
+
+// confirm ensures that the user has confirmed destruction of the
+// controller, either by supplying the controller name with --confirm,
+// by specifying --yes, or by answering the interactive prompt.
+func (c *destroyCommandBase) confirm(ctx *cmd.Context) error {
+	if c.confirmName != "" {
+		if c.confirmName != c.ControllerName() {
+			return errors.Errorf(
+				"controller destruction aborted: %q does not match controller name %q",
+				c.confirmName, c.ControllerName(),
+			)
+		}
+		return nil
+	}
+	if c.assumeYes {
+		return nil
+	}
+	answers, err := c.answers(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return confirmDestruction(ctx, answers, c.ControllerName())
+}
+
+// answers returns the scanner from which the answers to confirmation
+// prompts are read: stdin, or the file descriptor given with
+// --confirm-fd.
+func (c *destroyCommandBase) answers(ctx *cmd.Context) (*bufio.Scanner, error) {
+	if c.answerScanner != nil {
+		return c.answerScanner, nil
+	}
+	answers := ctx.Stdin
+	if c.confirmFD >= 0 {
+		f := os.NewFile(uintptr(c.confirmFD), "confirm-fd")
+		if _, err := f.Stat(); err != nil {
+			return nil, errors.Errorf(
+				"controller destruction aborted: --confirm-fd %d is not an open file descriptor",
+				c.confirmFD,
+			)
+		}
+		c.answerFile = f
+		answers = f
+	}
+	c.answerScanner = bufio.NewScanner(answers)
+	return c.answerScanner, nil
+}
+
+// closeAnswers closes the file opened on --confirm-fd, if any.
+func (c *destroyCommandBase) closeAnswers() {
+	if c.answerFile == nil {
+		return
+	}
+	if err := c.answerFile.Close(); err != nil {
+		logger.Debugf("closing --confirm-fd: %v", err)
+	}
+	c.answerFile = nil
+	c.answerScanner = nil
 }
 
-func confirmDestruction(ctx *cmd.Context, controllerName string) error {
+// confirmDestruction prompts the user on ctx.Stdout and reads their answer
+// from scanner. Anything other than a yes, including no answer at all,
+// aborts the destruction.
+func confirmDestruction(ctx *cmd.Context, scanner *bufio.Scanner, controllerName string) error {
 	// Get confirmation from the user that they want to continue
 	fmt.Fprintf(ctx.Stdout, destroySysMsg, controllerName)
+	return readConfirmation(scanner)
+}
 
-	scanner := bufio.NewScanner(ctx.Stdin)
+// readConfirmation reads an answer from scanner, returning an error
+// unless it is a yes.
+func readConfirmation(scanner *bufio.Scanner) error {
 	scanner.Scan()
 	err := scanner.Err()
 	if err != nil && err != io.EOF {
@@ -5,6 +5,13 @@ package controller_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/juju/cmd"
@@ -19,10 +26,16 @@ import (
 	"github.com/juju/juju/cmd/juju/controller"
 	"github.com/juju/juju/cmd/modelcmd"
 	cmdtesting "github.com/juju/juju/cmd/testing"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/juju/osenv"
 	"github.com/juju/juju/jujuclient"
 	"github.com/juju/juju/jujuclient/jujuclienttesting"
 	_ "github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/storage"
+	dummystorage "github.com/juju/juju/storage/provider/dummy"
+	"github.com/juju/juju/storage/provider/registry"
 	"github.com/juju/juju/testing"
 )
 
@@ -117,6 +130,91 @@ func (f *fakeDestroyAPIClient) DestroyModel() error {
 	return f.err
 }
 
+// fakeDestroyBackupsAPI mocks out the backups API
+type fakeDestroyBackupsAPI struct {
+	gitjujutesting.Stub
+	archive string
+}
+
+func (f *fakeDestroyBackupsAPI) Close() error {
+	f.MethodCall(f, "Close")
+	return f.NextErr()
+}
+
+func (f *fakeDestroyBackupsAPI) Create(notes string) (*params.BackupsMetadataResult, error) {
+	f.MethodCall(f, "Create", notes)
+	if err := f.NextErr(); err != nil {
+		return nil, err
+	}
+	return &params.BackupsMetadataResult{ID: "backup-id"}, nil
+}
+
+func (f *fakeDestroyBackupsAPI) Download(id string) (io.ReadCloser, error) {
+	f.MethodCall(f, "Download", id)
+	if err := f.NextErr(); err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(strings.NewReader(f.archive)), nil
+}
+
+// fakeDestroyStorageAPI mocks out the storage API of a model.
+type fakeDestroyStorageAPI struct {
+	*gitjujutesting.Stub
+	storage []params.StorageDetails
+}
+
+func (f *fakeDestroyStorageAPI) Close() error {
+	f.MethodCall(f, "Close")
+	return f.NextErr()
+}
+
+func (f *fakeDestroyStorageAPI) ListStorageDetails() ([]params.StorageDetails, error) {
+	f.MethodCall(f, "ListStorageDetails")
+	return f.storage, f.NextErr()
+}
+
+// fakeEnviron mocks out the parts of an environ used when destroying
+// a controller.
+type fakeEnviron struct {
+	environs.Environ
+	allInstances []instance.Instance
+	instances    []instance.Instance
+	err          error
+	destroyErr   error
+}
+
+func (e *fakeEnviron) Config() *config.Config {
+	cfg, err := config.New(config.NoDefaults, testing.FakeConfig())
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func (e *fakeEnviron) AllInstances() ([]instance.Instance, error) {
+	if len(e.allInstances) == 0 {
+		return nil, environs.ErrNoInstances
+	}
+	return e.allInstances, nil
+}
+
+func (e *fakeEnviron) Instances(ids []instance.Id) ([]instance.Instance, error) {
+	return e.instances, e.err
+}
+
+func (e *fakeEnviron) Destroy() error {
+	return e.destroyErr
+}
+
+type fakeInstance struct {
+	instance.Instance
+	id instance.Id
+}
+
+func (i fakeInstance) Id() instance.Id {
+	return i.id
+}
+
 func createBootstrapInfo(c *gc.C, name string) map[string]interface{} {
 	cfg, err := config.New(config.UseDefaults, map[string]interface{}{
 		"type":            "dummy",
@@ -260,6 +358,22 @@ func (s *DestroySuite) TestDestroyCannotConnectToAPI(c *gc.C) {
 	checkControllerExistsInStore(c, "local.test1", s.store)
 }
 
+func (s *DestroySuite) TestDestroyCannotConnectToAPIUnauthorized(c *gc.C) {
+	for i, apierr := range []error{
+		&params.Error{Code: params.CodeUnauthorized, Message: "invalid entity name or password"},
+		&params.Error{Code: params.CodeForbidden, Message: "permission denied"},
+		errors.Unauthorizedf("not logged in"),
+	} {
+		c.Logf("test %d: %v", i, apierr)
+		s.apierror = apierr
+		_, err := s.runDestroyCommand(c, "local.test1", "-y")
+		c.Assert(err, gc.ErrorMatches, "cannot connect to API: .*")
+		c.Check(c.GetTestLog(), jc.Contains, "refused the credentials")
+		c.Check(c.GetTestLog(), gc.Not(jc.Contains), "juju kill-controller")
+		checkControllerExistsInStore(c, "local.test1", s.store)
+	}
+}
+
 func (s *DestroySuite) TestDestroy(c *gc.C) {
 	_, err := s.runDestroyCommand(c, "local.test1", "-y")
 	c.Assert(err, jc.ErrorIsNil)
@@ -268,6 +382,60 @@ func (s *DestroySuite) TestDestroy(c *gc.C) {
 	checkControllerRemovedFromStore(c, "local.test1", s.store)
 }
 
+func (s *DestroySuite) TestDestroyWithBackup(c *gc.C) {
+	backupsapi := &fakeDestroyBackupsAPI{archive: "archive data"}
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz")
+	command := controller.NewDestroyCommandWithBackupsForTest(s.api, s.clientapi, backupsapi, s.store)
+	_, err := testing.RunCommand(c, command, "local.test1", "-y", "--backup", filename)
+	c.Assert(err, jc.ErrorIsNil)
+
+	backupsapi.CheckCallNames(c, "Create", "Download", "Close")
+	backupsapi.CheckCall(c, 0, "Create", `backup of controller "local.test1" before destruction`)
+	backupsapi.CheckCall(c, 1, "Download", "backup-id")
+	data, err := ioutil.ReadFile(filename)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "archive data")
+	s.api.CheckCall(c, 0, "DestroyController", false)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyBackupFailureAborts(c *gc.C) {
+	backupsapi := &fakeDestroyBackupsAPI{}
+	backupsapi.SetErrors(errors.New("no space left"))
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz")
+	command := controller.NewDestroyCommandWithBackupsForTest(s.api, s.clientapi, backupsapi, s.store)
+	_, err := testing.RunCommand(c, command, "local.test1", "-y", "--backup", filename)
+	c.Assert(err, gc.ErrorMatches, "cannot back up controller, aborting destruction: no space left")
+
+	backupsapi.CheckCallNames(c, "Create", "Close")
+	for _, call := range s.api.Calls() {
+		c.Check(call.FuncName, gc.Not(gc.Equals), "DestroyController")
+	}
+	_, err = os.Stat(filename)
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyBackupFailureIgnored(c *gc.C) {
+	backupsapi := &fakeDestroyBackupsAPI{}
+	backupsapi.SetErrors(nil, errors.New("download failed"))
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz")
+	command := controller.NewDestroyCommandWithBackupsForTest(s.api, s.clientapi, backupsapi, s.store)
+	ctx, err := testing.RunCommand(c, command, "local.test1", "-y", "--backup", filename, "--ignore-backup-failure")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(testing.Stderr(ctx), jc.Contains, "WARNING: cannot back up controller: download failed")
+	s.api.CheckCall(c, 0, "DestroyController", false)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyIgnoreBackupFailureRequiresBackup(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--ignore-backup-failure")
+	c.Assert(err, gc.ErrorMatches, "--ignore-backup-failure can only be used with --backup")
+	_, err = s.runDestroyCommand(c, "local.test1", "-y", "--ignore-backup-failure", "--destroy-all-models")
+	c.Assert(err, gc.ErrorMatches, "--ignore-backup-failure can only be used with --backup")
+}
+
 func (s *DestroySuite) TestDestroyAlias(c *gc.C) {
 	_, err := s.runDestroyCommand(c, "test1", "-y")
 	c.Assert(err, jc.ErrorIsNil)
@@ -283,6 +451,385 @@ func (s *DestroySuite) TestDestroyWithDestroyAllEnvsFlag(c *gc.C) {
 	checkControllerRemovedFromStore(c, "local.test1", s.store)
 }
 
+func (s *DestroySuite) TestDestroyInvalidTimeout(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--timeout", "0s")
+	c.Assert(err, gc.ErrorMatches, "--timeout must be positive, got 0")
+}
+
+func (s *DestroySuite) TestDestroyTimesOut(c *gc.C) {
+	for uuid, status := range s.api.envStatus {
+		status.Life = params.Dying
+		s.api.envStatus[uuid] = status
+	}
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--destroy-all-models", "--timeout", "1ms")
+	c.Assert(err, gc.ErrorMatches, `(?s)timed out after 1ms waiting for hosted models to be reclaimed.*`+
+		`owner@local/test2:test2 \(dying\).*juju kill-controller.*`)
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyInvalidPollInterval(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--poll-interval", "0s")
+	c.Assert(err, gc.ErrorMatches, "--poll-interval must be positive, got 0")
+}
+
+func (s *DestroySuite) TestDestroyPollInterval(c *gc.C) {
+	for uuid, status := range s.api.envStatus {
+		status.Life = params.Dying
+		s.api.envStatus[uuid] = status
+	}
+	ctx, err := s.runDestroyCommand(c, "local.test1", "-y", "--destroy-all-models",
+		"--timeout", "100ms", "--poll-interval", "1ms", "--progress-format", "json")
+	c.Assert(err, gc.ErrorMatches, `(?s)timed out after 100ms waiting for hosted models to be reclaimed.*`)
+
+	// The default interval of 2s would only have allowed one check.
+	lines := strings.Split(strings.TrimSpace(testing.Stdout(ctx)), "\n")
+	c.Assert(len(lines) > 1, jc.IsTrue)
+}
+
+func (s *DestroySuite) TestDestroyWarnsWhenReclamationStalls(c *gc.C) {
+	for uuid, status := range s.api.envStatus {
+		status.Life = params.Dying
+		s.api.envStatus[uuid] = status
+	}
+	ctx, err := s.runDestroyCommand(c, "local.test1", "-y", "--destroy-all-models",
+		"--timeout", "200ms", "--poll-interval", "1ms")
+	c.Assert(err, gc.ErrorMatches, `(?s)timed out after 200ms waiting for hosted models to be reclaimed.*`)
+	c.Assert(testing.Stderr(ctx), jc.Contains,
+		"WARNING: no resources reclaimed in the last 5 checks, reclamation may be stalled")
+}
+
+func (s *DestroySuite) TestDestroyUnknownProgressFormat(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--progress-format", "xml")
+	c.Assert(err, gc.ErrorMatches, `unknown progress format "xml"`)
+}
+
+func (s *DestroySuite) TestDestroyProgressJSON(c *gc.C) {
+	for uuid, status := range s.api.envStatus {
+		status.Life = params.Dying
+		s.api.envStatus[uuid] = status
+	}
+	ctx, err := s.runDestroyCommand(c, "local.test1", "-y", "--destroy-all-models", "--timeout", "1ms", "--progress-format", "json")
+	c.Assert(err, gc.ErrorMatches, `(?s)timed out after 1ms waiting for hosted models to be reclaimed.*`)
+
+	lines := strings.Split(strings.TrimSpace(testing.Stdout(ctx)), "\n")
+	c.Assert(lines, gc.HasLen, 1)
+	var progress struct {
+		Controller struct {
+			UUID             string      `json:"uuid"`
+			HostedModelCount int         `json:"hosted-model-count"`
+			Life             params.Life `json:"life"`
+		} `json:"controller"`
+		Models []struct {
+			Name string      `json:"name"`
+			Life params.Life `json:"life"`
+		} `json:"models"`
+		Complete bool `json:"complete"`
+	}
+	err = json.Unmarshal([]byte(lines[0]), &progress)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(progress.Controller.UUID, gc.Equals, test1UUID)
+	c.Check(progress.Controller.HostedModelCount, gc.Equals, 2)
+	c.Check(progress.Models, gc.HasLen, 2)
+	for _, model := range progress.Models {
+		c.Check(model.Life, gc.Equals, params.Dying)
+	}
+	c.Check(progress.Complete, jc.IsFalse)
+	c.Check(testing.Stderr(ctx), gc.Not(jc.Contains), "Waiting on")
+}
+
+func (s *DestroySuite) TestDestroyProgressJSONComplete(c *gc.C) {
+	ctx, err := s.runDestroyCommand(c, "local.test1", "-y", "--progress-format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+
+	lines := strings.Split(strings.TrimSpace(testing.Stdout(ctx)), "\n")
+	c.Assert(lines, gc.HasLen, 1)
+	var progress struct {
+		Complete bool `json:"complete"`
+	}
+	err = json.Unmarshal([]byte(lines[0]), &progress)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(progress.Complete, jc.IsTrue)
+}
+
+func (s *DestroySuite) TestDestroyNoWait(c *gc.C) {
+	for uuid, status := range s.api.envStatus {
+		status.Life = params.Dying
+		s.api.envStatus[uuid] = status
+	}
+	ctx, err := s.runDestroyCommand(c, "local.test1", "-y", "--destroy-all-models", "--no-wait")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.api.destroyAll, jc.IsTrue)
+	c.Check(testing.Stderr(ctx), jc.Contains, `Destruction of controller "local.test1" initiated`)
+	c.Check(testing.Stderr(ctx), jc.Contains, `Run "juju destroy-controller local.test1" again to wait for it to complete`)
+	c.Check(testing.Stderr(ctx), gc.Not(jc.Contains), "Waiting for hosted model resources")
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyNoWaitAliveModels(c *gc.C) {
+	for uuid, status := range s.api.envStatus {
+		status.Life = params.Alive
+		s.api.envStatus[uuid] = status
+	}
+	s.api.SetErrors(&params.Error{Code: params.CodeHasHostedModels})
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--no-wait")
+	c.Assert(err, gc.ErrorMatches, `(?s)cannot destroy controller "local.test1".*live hosted models.*`)
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) makeLargeHostedModel() {
+	status := s.api.envStatus[test2UUID]
+	status.Life = params.Dying
+	status.HostedMachineCount = 8
+	status.ServiceCount = 5
+	s.api.envStatus[test2UUID] = status
+}
+
+func (s *DestroySuite) runLargeDestroyCommand(c *gc.C, answers string, args ...string) (*cmd.Context, error) {
+	var stdin, stdout bytes.Buffer
+	ctx := testing.Context(c)
+	ctx.Stdout = &stdout
+	ctx.Stdin = &stdin
+	stdin.WriteString(answers)
+
+	command := controller.NewDestroyCommandWithFootprintForTest(s.api, s.clientapi, s.store)
+	_, errc := cmdtesting.RunCommand(ctx, command, args...)
+	select {
+	case err := <-errc:
+		return ctx, err
+	case <-time.After(testing.LongWait):
+		c.Fatalf("command took too long")
+	}
+	return nil, nil
+}
+
+func (s *DestroySuite) TestDestroyLargeControllerRequiresName(c *gc.C) {
+	s.makeLargeHostedModel()
+	ctx, err := s.runLargeDestroyCommand(c, "y\nlocal.test1\n", "local.test1", "--destroy-all-models", "--no-wait")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), jc.Contains, `WARNING! The "local.test1" controller has 1 hosted model(s) with 8 machine(s)
+and 5 service(s), which will all be destroyed.
+
+Type the controller name to continue: `)
+	c.Assert(s.api.destroyAll, jc.IsTrue)
+}
+
+func (s *DestroySuite) TestDestroyLargeControllerNameMismatch(c *gc.C) {
+	s.makeLargeHostedModel()
+	_, err := s.runLargeDestroyCommand(c, "y\n", "local.test1", "-y", "--destroy-all-models")
+	c.Assert(err, gc.ErrorMatches, `controller destruction aborted: "y" does not match controller name "local.test1"`)
+	for _, call := range s.api.Calls() {
+		c.Check(call.FuncName, gc.Not(gc.Equals), "DestroyController")
+	}
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyLargeControllerConfirmName(c *gc.C) {
+	s.makeLargeHostedModel()
+	ctx, err := s.runLargeDestroyCommand(c, "", "local.test1", "--confirm", "local.test1", "--destroy-all-models", "--no-wait")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Not(jc.Contains), "Type the controller name")
+	c.Assert(s.api.destroyAll, jc.IsTrue)
+}
+
+func (s *DestroySuite) TestDestroyLargeControllerForce(c *gc.C) {
+	s.makeLargeHostedModel()
+	ctx, err := s.runLargeDestroyCommand(c, "", "local.test1", "-y", "--force", "--destroy-all-models", "--no-wait")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Not(jc.Contains), "Type the controller name")
+	c.Assert(s.api.destroyAll, jc.IsTrue)
+}
+
+func (s *DestroySuite) TestDestroyForceRequiresDestroyAllModels(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--force")
+	c.Assert(err, gc.ErrorMatches, "--force can only be used with --destroy-all-models")
+}
+
+func (s *DestroySuite) TestDestroyBelowConfirmThreshold(c *gc.C) {
+	s.makeLargeHostedModel()
+	ctx, err := s.runLargeDestroyCommand(c, "", "local.test1", "-y", "--confirm-threshold", "13", "--destroy-all-models", "--no-wait")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Not(jc.Contains), "Type the controller name")
+	c.Assert(s.api.destroyAll, jc.IsTrue)
+}
+
+func (s *DestroySuite) TestDestroyNegativeConfirmThreshold(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--confirm-threshold", "-1")
+	c.Assert(err, gc.ErrorMatches, "--confirm-threshold must not be negative, got -1")
+}
+
+func (s *DestroySuite) newDestroyCommandWithStorage(stub *gitjujutesting.Stub) cmd.Command {
+	storage := map[string][]params.StorageDetails{
+		test2UUID: {{
+			StorageTag: "storage-data-0",
+			Kind:       params.StorageKindBlock,
+			Persistent: true,
+		}, {
+			StorageTag: "storage-cache-0",
+			Kind:       params.StorageKindFilesystem,
+		}},
+		test3UUID: {{
+			StorageTag: "storage-logs-1",
+			Kind:       params.StorageKindFilesystem,
+			Persistent: true,
+		}},
+	}
+	return controller.NewDestroyCommandWithStorageForTest(
+		s.api, s.clientapi,
+		func(modelUUID string) (controller.DestroyStorageAPI, error) {
+			stub.AddCall("NewStorageAPI", modelUUID)
+			return &fakeDestroyStorageAPI{Stub: stub, storage: storage[modelUUID]}, nil
+		},
+		s.store,
+	)
+}
+
+func (s *DestroySuite) TestDestroyPersistentStorageWarning(c *gc.C) {
+	stub := &gitjujutesting.Stub{}
+	ctx, err := testing.RunCommand(c, s.newDestroyCommandWithStorage(stub), "local.test1", "-y")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stderr(ctx), jc.Contains, `WARNING: the following persistent storage will not be destroyed
+with the controller, and will remain in the cloud until removed:
+	test2:test2: data/0 (block)
+	test3:admin: logs/1 (filesystem)
+`)
+	c.Check(testing.Stderr(ctx), gc.Not(jc.Contains), "cache/0")
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) runDestroyCommandWithStorage(c *gc.C, answers string) (*cmd.Context, error) {
+	var stdin, stdout bytes.Buffer
+	ctx := testing.Context(c)
+	ctx.Stdout = &stdout
+	ctx.Stdin = &stdin
+	stdin.WriteString(answers)
+
+	stub := &gitjujutesting.Stub{}
+	_, errc := cmdtesting.RunCommand(ctx, s.newDestroyCommandWithStorage(stub), "local.test1")
+	select {
+	case err := <-errc:
+		return ctx, err
+	case <-time.After(testing.LongWait):
+		c.Fatalf("command took too long")
+	}
+	return nil, nil
+}
+
+func (s *DestroySuite) TestDestroyPersistentStoragePromptsAgain(c *gc.C) {
+	ctx, err := s.runDestroyCommandWithStorage(c, "y\ny\n")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stderr(ctx), jc.Contains, "WARNING: the following persistent storage will not be destroyed")
+	c.Check(testing.Stdout(ctx), jc.Contains, "Continue destroying the controller, leaving the storage above [y/N]? ")
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyPersistentStorageDeclined(c *gc.C) {
+	ctx, err := s.runDestroyCommandWithStorage(c, "y\nn\n")
+	c.Assert(err, gc.ErrorMatches, "controller destruction aborted")
+	c.Check(testing.Stdout(ctx), jc.Contains, "Continue destroying the controller, leaving the storage above [y/N]? ")
+	for _, call := range s.api.Calls() {
+		c.Check(call.FuncName, gc.Not(gc.Equals), "DestroyController")
+	}
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyPersistentStorageCheckFails(c *gc.C) {
+	stub := &gitjujutesting.Stub{}
+	stub.SetErrors(errors.New("boom"))
+	ctx, err := testing.RunCommand(c, s.newDestroyCommandWithStorage(stub), "local.test1", "-y")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stderr(ctx), jc.Contains, `WARNING: cannot check for persistent storage: cannot list storage in model "local.test1:admin": boom`)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyKeepModelsEmptyName(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--keep-models", "test2:test2,")
+	c.Assert(err, gc.ErrorMatches, "empty model name in --keep-models")
+}
+
+func (s *DestroySuite) TestDestroyKeepModelsNotMigrated(c *gc.C) {
+	status := s.api.envStatus[test2UUID]
+	status.Life = params.Alive
+	s.api.envStatus[test2UUID] = status
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--destroy-all-models", "--keep-models", "test2:test2")
+	c.Assert(err, gc.ErrorMatches, `(?s)cannot destroy controller "local.test1".*`+
+		`have not\nbeen migrated off the controller:\n\ttest2:test2 \(`+test2UUID+`\).*`)
+	s.api.CheckCallNames(c, "AllModels", "ModelStatus", "Close")
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyKeepModelsMigrated(c *gc.C) {
+	// Models no longer known to the controller have been migrated.
+	ctx, err := s.runDestroyCommand(c, "local.test1", "-y", "--keep-models", "gone,"+test2UUID)
+	c.Assert(err, jc.ErrorIsNil)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+
+	// A name that matches no model may be a typo, so it is reported.
+	c.Check(testing.Stderr(ctx), jc.Contains, `WARNING: the controller has no model "gone"`)
+	c.Check(strings.Count(testing.Stderr(ctx), "WARNING: the controller has no model"), gc.Equals, 1)
+}
+
+// migratingDestroyAPI reports a model as migrated away once
+// ModelStatus has been called a given number of times.
+type migratingDestroyAPI struct {
+	*fakeDestroyAPI
+	uuid       string
+	migratedAt int
+	polls      int
+}
+
+func (f *migratingDestroyAPI) ModelStatus(tags ...names.ModelTag) ([]base.ModelStatus, error) {
+	f.polls++
+	if f.polls == f.migratedAt {
+		status := f.envStatus[f.uuid]
+		status.Life = params.Dead
+		f.envStatus[f.uuid] = status
+	}
+	return f.fakeDestroyAPI.ModelStatus(tags...)
+}
+
+func (s *DestroySuite) TestDestroyKeepModelsWaitForMigration(c *gc.C) {
+	s.PatchValue(controller.MigrationPollInterval, time.Millisecond)
+	status := s.api.envStatus[test2UUID]
+	status.Life = params.Alive
+	s.api.envStatus[test2UUID] = status
+	api := &migratingDestroyAPI{fakeDestroyAPI: s.api, uuid: test2UUID, migratedAt: 3}
+
+	command := controller.NewDestroyCommandForTest(api, s.clientapi, s.store, nil)
+	ctx, err := testing.RunCommand(c, command, "local.test1", "-y", "--keep-models", "test2:test2", "--wait-for-migration", "1m")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stderr(ctx), jc.Contains, "Waiting for 1 model(s) to be migrated off the controller")
+	c.Check(s.api.Calls()[:6], jc.DeepEquals, []gitjujutesting.StubCall{
+		{FuncName: "AllModels"},
+		{FuncName: "ModelStatus", Args: []interface{}{[]names.ModelTag{names.NewModelTag(test2UUID)}}},
+		{FuncName: "AllModels"},
+		{FuncName: "ModelStatus", Args: []interface{}{[]names.ModelTag{names.NewModelTag(test2UUID)}}},
+		{FuncName: "AllModels"},
+		{FuncName: "ModelStatus", Args: []interface{}{[]names.ModelTag{names.NewModelTag(test2UUID)}}},
+	})
+	s.api.CheckCall(c, 6, "DestroyController", false)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyKeepModelsWaitForMigrationTimesOut(c *gc.C) {
+	s.PatchValue(controller.MigrationPollInterval, time.Millisecond)
+	status := s.api.envStatus[test2UUID]
+	status.Life = params.Alive
+	s.api.envStatus[test2UUID] = status
+
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--keep-models", "test2:test2", "--wait-for-migration", "10ms")
+	c.Assert(err, gc.ErrorMatches, `(?s)cannot destroy controller "local.test1".*`+
+		`have not\nbeen migrated off the controller:\n\ttest2:test2 \(`+test2UUID+`\).*`)
+	for _, call := range s.api.Calls() {
+		c.Check(call.FuncName, gc.Not(gc.Equals), "DestroyController")
+	}
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyWaitForMigrationRequiresKeepModels(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--wait-for-migration", "1m")
+	c.Assert(err, gc.ErrorMatches, "--wait-for-migration can only be used with --keep-models")
+}
+
 func (s *DestroySuite) TestDestroyControllerGetFails(c *gc.C) {
 	s.api.SetErrors(errors.NotFoundf(`controller "test3"`))
 	_, err := s.runDestroyCommand(c, "test3", "-y")
@@ -292,6 +839,177 @@ func (s *DestroySuite) TestDestroyControllerGetFails(c *gc.C) {
 	checkControllerExistsInStore(c, "test3", s.store)
 }
 
+func (s *DestroySuite) TestGetControllerEnvironCached(c *gc.C) {
+	s.api.env = createBootstrapInfo(c, "admin")
+	base := controller.NewDestroyCommandBaseForTest(s.api)
+
+	// test3 has no bootstrap config in the store, so the
+	// config must come from the API; but only the once.
+	env1, err := base.GetControllerEnviron(s.store, "test3")
+	c.Assert(err, jc.ErrorIsNil)
+	env2, err := base.GetControllerEnviron(s.store, "test3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env2, gc.Equals, env1)
+	s.api.CheckCallNames(c, "ModelConfig")
+}
+
+// fakeFilesystemSource is a filesystem source that can list
+// its filesystems.
+type fakeFilesystemSource struct {
+	storage.FilesystemSource
+	list func() ([]string, error)
+}
+
+func (s fakeFilesystemSource) ListFilesystems() ([]string, error) {
+	return s.list()
+}
+
+// registerReclaimableStorage registers an environ-scoped storage
+// provider for fakeEnviron, whose volumes and filesystems are listed
+// by the given functions.
+func (s *DestroySuite) registerReclaimableStorage(volumes, filesystems func() ([]string, error)) {
+	registry.RegisterProvider("reclaimable", &dummystorage.StorageProvider{
+		StorageScope: storage.ScopeEnviron,
+		IsDynamic:    true,
+		VolumeSourceFunc: func(*config.Config, *storage.Config) (storage.VolumeSource, error) {
+			return &dummystorage.VolumeSource{ListVolumesFunc: volumes}, nil
+		},
+		FilesystemSourceFunc: func(*config.Config, *storage.Config) (storage.FilesystemSource, error) {
+			return fakeFilesystemSource{list: filesystems}, nil
+		},
+	})
+	registry.RegisterEnvironStorageProviders("someprovider", "reclaimable")
+	s.AddCleanup(func(*gc.C) {
+		registry.RegisterProvider("reclaimable", nil)
+		registry.ResetEnvironStorageProviders("someprovider")
+	})
+}
+
+func (s *DestroySuite) TestReportReclaimed(c *gc.C) {
+	env := &fakeEnviron{
+		allInstances: []instance.Instance{
+			fakeInstance{id: "i-0"}, fakeInstance{id: "i-1"}, fakeInstance{id: "i-2"},
+		},
+	}
+	volumes := []string{"vol-0", "vol-1"}
+	filesystems := []string{"fs-0"}
+	s.registerReclaimableStorage(
+		func() ([]string, error) { return volumes, nil },
+		func() ([]string, error) { return filesystems, nil },
+	)
+	snapshot := controller.SnapshotResources(env)
+
+	// Destroying the environ leaves an instance and a volume behind.
+	env.instances = []instance.Instance{nil, fakeInstance{id: "i-1"}, nil}
+	env.err = environs.ErrPartialInstances
+	volumes = []string{"vol-1"}
+	filesystems = nil
+
+	ctx := testing.Context(c)
+	controller.ReportReclaimed(ctx, env, snapshot)
+	c.Check(testing.Stderr(ctx), gc.Equals, `
+Terminated 2 instance(s): i-0, i-2
+Removed 1 volume(s): vol-0
+Removed 1 filesystem(s): fs-0
+WARNING: the provider could not confirm that the following
+resources were removed:
+  instances: i-1
+  volumes: vol-1
+
+Review your cloud provider console for any resources that
+need to be cleaned up.
+`[1:])
+}
+
+func (s *DestroySuite) TestReportReclaimedAll(c *gc.C) {
+	env := &fakeEnviron{
+		allInstances: []instance.Instance{fakeInstance{id: "i-0"}, fakeInstance{id: "i-1"}},
+	}
+	snapshot := controller.SnapshotResources(env)
+	env.err = environs.ErrNoInstances
+	ctx := testing.Context(c)
+	controller.ReportReclaimed(ctx, env, snapshot)
+	c.Check(testing.Stderr(ctx), gc.Equals, "Terminated 2 instance(s): i-0, i-1\n")
+}
+
+func (s *DestroySuite) TestReportReclaimedUnconfirmed(c *gc.C) {
+	env := &fakeEnviron{allInstances: []instance.Instance{fakeInstance{id: "i-0"}}}
+	listed := false
+	s.registerReclaimableStorage(
+		func() ([]string, error) {
+			if listed {
+				return nil, errors.New("environ destroyed")
+			}
+			listed = true
+			return []string{"vol-0"}, nil
+		},
+		func() ([]string, error) { return nil, nil },
+	)
+	snapshot := controller.SnapshotResources(env)
+	env.err = errors.New("environ destroyed")
+	ctx := testing.Context(c)
+	controller.ReportReclaimed(ctx, env, snapshot)
+	c.Check(testing.Stderr(ctx), jc.Contains, "  instances: i-0\n  volumes: vol-0\n")
+	c.Check(testing.Stderr(ctx), gc.Not(jc.Contains), "Terminated")
+	c.Check(testing.Stderr(ctx), gc.Not(jc.Contains), "Removed")
+}
+
+func (s *DestroySuite) TestDestroyEnvironFailureResumes(c *gc.C) {
+	env := &fakeEnviron{destroyErr: errors.New("rate limit exceeded")}
+	command := controller.NewDestroyCommandWithEnvironForTest(s.api, env, s.store, nil)
+	_, err := testing.RunCommand(c, command, "local.test1", "-y")
+	c.Assert(err, gc.ErrorMatches, `(?s)cannot destroy cloud resources for controller "local.test1": rate limit exceeded.*`+
+		`Run this command again to resume.*`)
+	checkControllerExistsInStore(c, "local.test1", s.store)
+	s.api.CheckCall(c, 0, "DestroyController", false)
+
+	// The API server is no longer reachable, but the command picks
+	// up where it left off.
+	s.api.ResetCalls()
+	env.destroyErr = nil
+	command = controller.NewDestroyCommandWithEnvironForTest(s.api, env, s.store, errors.New("connection refused"))
+	ctx, err := testing.RunCommand(c, command, "local.test1", "-y")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stderr(ctx), jc.Contains, `Resuming destruction of controller "local.test1"`)
+	s.api.CheckNoCalls(c)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+	_, err = os.Stat(filepath.Join(osenv.JujuXDGDataHomeDir(), "destroying", "local.test1"))
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *DestroySuite) TestDestroyResumeRerunsChecks(c *gc.C) {
+	dir := filepath.Join(osenv.JujuXDGDataHomeDir(), "destroying")
+	c.Assert(os.MkdirAll(dir, 0700), jc.ErrorIsNil)
+	err := ioutil.WriteFile(filepath.Join(dir, "local.test1"), []byte(test1UUID), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	backupsapi := &fakeDestroyBackupsAPI{}
+	backupsapi.SetErrors(errors.New("no space left"))
+	filename := filepath.Join(c.MkDir(), "backup.tar.gz")
+	command := controller.NewDestroyCommandWithBackupsForTest(s.api, s.clientapi, backupsapi, s.store)
+	ctx, err := testing.RunCommand(c, command, "local.test1", "-y", "--backup", filename)
+	c.Assert(err, gc.ErrorMatches, "cannot back up controller, aborting destruction: no space left")
+	c.Check(testing.Stderr(ctx), jc.Contains, `Resuming destruction of controller "local.test1"`)
+
+	backupsapi.CheckCallNames(c, "Create", "Close")
+	checkControllerExistsInStore(c, "local.test1", s.store)
+	// The next run starts from the beginning.
+	_, err = os.Stat(filepath.Join(dir, "local.test1"))
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *DestroySuite) TestDestroyIgnoresResumeRecordForOtherController(c *gc.C) {
+	dir := filepath.Join(osenv.JujuXDGDataHomeDir(), "destroying")
+	c.Assert(os.MkdirAll(dir, 0700), jc.ErrorIsNil)
+	err := ioutil.WriteFile(filepath.Join(dir, "local.test1"), []byte(test3UUID), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.runDestroyCommand(c, "local.test1", "-y")
+	c.Assert(err, jc.ErrorIsNil)
+	s.api.CheckCall(c, 0, "DestroyController", false)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
 func (s *DestroySuite) TestFailedDestroyController(c *gc.C) {
 	s.api.SetErrors(errors.New("permission denied"))
 	_, err := s.runDestroyCommand(c, "local.test1", "-y")
@@ -406,6 +1124,58 @@ func (s *DestroySuite) TestDestroyCommandConfirmation(c *gc.C) {
 	}
 }
 
+func (s *DestroySuite) TestDestroyConfirmFD(c *gc.C) {
+	for _, test := range []struct {
+		answer string
+		err    string
+	}{
+		{answer: "y\n"},
+		{answer: "n\n", err: "controller destruction aborted"},
+		{answer: "", err: "controller destruction aborted"},
+	} {
+		r, w, err := os.Pipe()
+		c.Assert(err, jc.ErrorIsNil)
+		_, err = w.WriteString(test.answer)
+		c.Assert(err, jc.ErrorIsNil)
+		w.Close()
+
+		// Stdin says no, but the answer is taken from the pipe.
+		ctx := testing.Context(c)
+		ctx.Stdin = strings.NewReader("n\n")
+		_, errc := cmdtesting.RunCommand(ctx, s.newDestroyCommand(), "local.test1", "--confirm-fd", fmt.Sprint(r.Fd()))
+		select {
+		case err := <-errc:
+			// The command closes the descriptor it was given, so
+			// closing it again fails.
+			c.Check(r.Close(), gc.NotNil)
+			if test.err == "" {
+				c.Check(err, jc.ErrorIsNil)
+				checkControllerRemovedFromStore(c, "local.test1", s.store)
+				s.resetController(c)
+			} else {
+				c.Check(err, gc.ErrorMatches, test.err)
+				checkControllerExistsInStore(c, "local.test1", s.store)
+			}
+		case <-time.After(testing.LongWait):
+			c.Fatalf("command took too long")
+		}
+	}
+}
+
+func (s *DestroySuite) TestDestroyConfirmFDNotOpen(c *gc.C) {
+	r, w, err := os.Pipe()
+	c.Assert(err, jc.ErrorIsNil)
+	w.Close()
+	fd := r.Fd()
+	r.Close()
+
+	_, err = s.runDestroyCommand(c, "local.test1", "--confirm-fd", fmt.Sprint(fd))
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		"controller destruction aborted: --confirm-fd %d is not an open file descriptor", fd,
+	))
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
 func (s *DestroySuite) TestBlockedDestroy(c *gc.C) {
 	s.api.SetErrors(&params.Error{Code: params.CodeOperationBlocked})
 	s.runDestroyCommand(c, "local.test1", "-y")
@@ -453,3 +1223,80 @@ func (s *DestroySuite) TestDestroyReturnsBlocks(c *gc.C) {
 		"test1  1871299e-1370-4f3e-83ab-1849ed7b1076  cheryl@local  destroy-model\n"+
 		"test2  c59d0e3b-2bd7-4867-b1b9-f1ef8a0bb004  bob@local     destroy-model,all-changes\n")
 }
+
+func (s *DestroySuite) TestDestroyReturnsBlocksJSON(c *gc.C) {
+	s.api.SetErrors(&params.Error{Code: params.CodeOperationBlocked})
+	s.api.blocks = []params.ModelBlockInfo{
+		params.ModelBlockInfo{
+			Name:     "test1",
+			UUID:     test1UUID,
+			OwnerTag: "cheryl@local",
+			Blocks: []string{
+				"BlockDestroy",
+				"BlockChange",
+			},
+		},
+	}
+	ctx, _ := s.runDestroyCommand(c, "local.test1", "-y", "--destroy-all-models", "--output-format", "json")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `[{"name":"test1","model-uuid":"1871299e-1370-4f3e-83ab-1849ed7b1076",`+
+		`"owner":"cheryl@local","blocks":["destroy-model","all-changes"]}]`+"\n")
+}
+
+func (s *DestroySuite) TestDestroyReturnsBlocksYAML(c *gc.C) {
+	s.api.SetErrors(&params.Error{Code: params.CodeOperationBlocked})
+	s.api.blocks = []params.ModelBlockInfo{
+		params.ModelBlockInfo{
+			Name:     "test1",
+			UUID:     test1UUID,
+			OwnerTag: "cheryl@local",
+			Blocks: []string{
+				"BlockDestroy",
+			},
+		},
+	}
+	ctx, _ := s.runDestroyCommand(c, "local.test1", "-y", "--destroy-all-models", "--output-format", "yaml")
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+- name: test1
+  model-uuid: 1871299e-1370-4f3e-83ab-1849ed7b1076
+  owner: cheryl@local
+  blocks:
+  - destroy-model
+`[1:])
+}
+
+func (s *DestroySuite) TestDestroyUnknownOutputFormat(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--output-format", "xml")
+	c.Assert(err, gc.ErrorMatches, `unknown output format "xml"`)
+}
+
+func (s *DestroySuite) TestDestroyDryRun(c *gc.C) {
+	status := s.api.envStatus[test2UUID]
+	status.Life = params.Alive
+	status.HostedMachineCount = 2
+	status.ServiceCount = 1
+	s.api.envStatus[test2UUID] = status
+	ctx, err := s.runDestroyCommand(c, "local.test1", "--dry-run")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+Controller "local.test1" (1871299e-1370-4f3e-83ab-1849ed7b1076) is dead
+Would destroy 1 model, 2 machines, 1 service
+Models:
+	owner@local/test2:test2 (alive), 2 machines, 1 service
+The controller has live hosted models; --destroy-all-models is required to destroy them.
+`[1:])
+	s.api.CheckCallNames(c, "AllModels", "ModelStatus", "ModelStatus", "Close")
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyConfirmName(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "--confirm", "local.test1")
+	c.Assert(err, jc.ErrorIsNil)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *DestroySuite) TestDestroyConfirmNameMismatch(c *gc.C) {
+	_, err := s.runDestroyCommand(c, "local.test1", "-y", "--confirm", "test3")
+	c.Assert(err, gc.ErrorMatches, `controller destruction aborted: "test3" does not match controller name "local.test1"`)
+	c.Assert(s.api.Calls(), gc.HasLen, 0)
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
@@ -9,9 +9,12 @@ import (
 
 	"github.com/juju/juju/api"
 	"github.com/juju/juju/cmd/modelcmd"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/jujuclient"
 )
 
+var MigrationPollInterval = &migrationPollInterval
+
 // NewListControllersCommandForTest returns a listControllersCommand with the clientstore provided
 // as specified.
 func NewListControllersCommandForTest(testStore jujuclient.ClientStore) *listControllersCommand {
@@ -88,6 +91,122 @@ func NewDestroyCommandForTest(
 			clientapi: clientapi,
 			apierr:    apierr,
 		},
+		listPersistentStorage: noPersistentStorage,
+		hostedFootprint:       noHostedFootprint,
+	}
+	cmd.SetClientStore(store)
+	return modelcmd.WrapController(
+		cmd,
+		modelcmd.ControllerSkipFlags,
+		modelcmd.ControllerSkipDefault,
+	)
+}
+
+// NewDestroyCommandWithStorageForTest returns a DestroyCommand with the
+// controller, client and storage endpoints mocked out.
+func NewDestroyCommandWithStorageForTest(
+	api destroyControllerAPI,
+	clientapi destroyClientAPI,
+	newStorageAPI func(modelUUID string) (DestroyStorageAPI, error),
+	store jujuclient.ClientStore,
+) cmd.Command {
+	cmd := &destroyCommand{
+		destroyCommandBase: destroyCommandBase{
+			api:       api,
+			clientapi: clientapi,
+		},
+		newStorageAPI: func(modelUUID string) (destroyStorageAPI, error) {
+			return newStorageAPI(modelUUID)
+		},
+		hostedFootprint: noHostedFootprint,
+	}
+	cmd.SetClientStore(store)
+	return modelcmd.WrapController(
+		cmd,
+		modelcmd.ControllerSkipFlags,
+		modelcmd.ControllerSkipDefault,
+	)
+}
+
+// NewDestroyCommandWithFootprintForTest returns a DestroyCommand with
+// the controller and client endpoints mocked out, which checks the size
+// of the hosted models using the controller API.
+func NewDestroyCommandWithFootprintForTest(
+	api destroyControllerAPI,
+	clientapi destroyClientAPI,
+	store jujuclient.ClientStore,
+) cmd.Command {
+	cmd := &destroyCommand{
+		destroyCommandBase: destroyCommandBase{
+			api:       api,
+			clientapi: clientapi,
+		},
+		listPersistentStorage: noPersistentStorage,
+	}
+	cmd.SetClientStore(store)
+	return modelcmd.WrapController(
+		cmd,
+		modelcmd.ControllerSkipFlags,
+		modelcmd.ControllerSkipDefault,
+	)
+}
+
+// DestroyStorageAPI is the storage API used by the destroy command.
+type DestroyStorageAPI destroyStorageAPI
+
+// noHostedFootprint reports that the hosted models are empty, without
+// calling the API.
+func noHostedFootprint(destroyControllerAPI, string) (ctrData, error) {
+	return ctrData{}, nil
+}
+
+// noPersistentStorage reports that there is no persistent storage,
+// without calling the API.
+func noPersistentStorage(destroyControllerAPI) ([]string, error) {
+	return nil, nil
+}
+
+// NewDestroyCommandWithBackupsForTest returns a DestroyCommand with the
+// controller, client and backups endpoints mocked out.
+func NewDestroyCommandWithBackupsForTest(
+	api destroyControllerAPI,
+	clientapi destroyClientAPI,
+	backupsapi destroyBackupsAPI,
+	store jujuclient.ClientStore,
+) cmd.Command {
+	cmd := &destroyCommand{
+		destroyCommandBase: destroyCommandBase{
+			api:       api,
+			clientapi: clientapi,
+		},
+		backupsapi:            backupsapi,
+		listPersistentStorage: noPersistentStorage,
+		hostedFootprint:       noHostedFootprint,
+	}
+	cmd.SetClientStore(store)
+	return modelcmd.WrapController(
+		cmd,
+		modelcmd.ControllerSkipFlags,
+		modelcmd.ControllerSkipDefault,
+	)
+}
+
+// NewDestroyCommandWithEnvironForTest returns a DestroyCommand with the
+// controller endpoints and the controller environ mocked out.
+func NewDestroyCommandWithEnvironForTest(
+	api destroyControllerAPI,
+	env environs.Environ,
+	store jujuclient.ClientStore,
+	apierr error,
+) cmd.Command {
+	cmd := &destroyCommand{
+		destroyCommandBase: destroyCommandBase{
+			api:               api,
+			apierr:            apierr,
+			controllerEnviron: env,
+		},
+		listPersistentStorage: noPersistentStorage,
+		hostedFootprint:       noHostedFootprint,
 	}
 	cmd.SetClientStore(store)
 	return modelcmd.WrapController(
@@ -118,6 +237,32 @@ func NewKillCommandForTest(
 	return wrapKillCommand(kill, apiOpen, clock)
 }
 
+// DestroyCommandBase exposes destroyCommandBase for testing.
+type DestroyCommandBase struct {
+	*destroyCommandBase
+}
+
+// NewDestroyCommandBaseForTest returns a DestroyCommandBase with the
+// controller endpoint mocked out.
+func NewDestroyCommandBaseForTest(api destroyControllerAPI) *DestroyCommandBase {
+	return &DestroyCommandBase{&destroyCommandBase{api: api}}
+}
+
+// GetControllerEnviron calls getControllerEnviron.
+func (c *DestroyCommandBase) GetControllerEnviron(store jujuclient.ClientStore, controllerName string) (environs.Environ, error) {
+	return c.getControllerEnviron(store, controllerName, c.api)
+}
+
+// SnapshotResources calls snapshotResources.
+func SnapshotResources(env environs.Environ) resourceSnapshot {
+	return snapshotResources(env)
+}
+
+// ReportReclaimed calls reportReclaimed.
+func ReportReclaimed(ctx *cmd.Context, env environs.Environ, snapshot resourceSnapshot) {
+	reportReclaimed(ctx, env, snapshot)
+}
+
 // NewListBlocksCommandForTest returns a ListBlocksCommand with the controller
 // endpoint mocked out.
 func NewListBlocksCommandForTest(api listBlocksAPI, apierr error, store jujuclient.ClientStore) cmd.Command {
@@ -136,6 +281,14 @@ func FmtCtrStatus(data CtrData) string {
 	return fmtCtrStatus(ctrData(data))
 }
 
+func FmtCtrStatusDelta(prev, cur CtrData) string {
+	return fmtCtrStatusDelta(ctrData(prev), ctrData(cur))
+}
+
+func SameResourceCounts(a, b CtrData) bool {
+	return sameResourceCounts(ctrData(a), ctrData(b))
+}
+
 func FmtModelStatus(data ModelData) string {
 	return fmtModelStatus(modelData(data))
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/jujuclient"
 )
 
 const killDoc = `
@@ -26,6 +27,14 @@ will be destroyed through the cloud provisioner.  If there are additional
 machines, including machines within hosted models, these machines will
 not be destroyed and will never be reconnected to the Juju controller being
 destroyed. 
+
+Instead of answering the interactive prompt, the controller name may be
+supplied with --confirm. Alternatively, --confirm-fd reads the answer to
+the prompt from the given file descriptor rather than from stdin.
+
+If a controller never fully came up, --dead-only destroys its provider
+resources without attempting to use the API server at all. It refuses to
+run if the API server can be reached; use destroy-controller instead.
 `
 
 // NewKillCommand returns a command to kill a controller. Killing is a forceful
@@ -57,6 +66,7 @@ func wrapKillCommand(kill *killCommand, apiOpen modelcmd.APIOpener, clock clock.
 // killCommand kills the specified controller.
 type killCommand struct {
 	destroyCommandBase
+	deadOnly bool
 }
 
 // Info implements Command.Info.
@@ -73,6 +83,9 @@ func (c *killCommand) Info() *cmd.Info {
 func (c *killCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.assumeYes, "y", false, "do not ask for confirmation")
 	f.BoolVar(&c.assumeYes, "yes", false, "")
+	f.StringVar(&c.confirmName, "confirm", "", "confirm destruction by supplying the controller name")
+	f.IntVar(&c.confirmFD, "confirm-fd", -1, "read the answer to the confirmation prompt from this file descriptor instead of stdin")
+	f.BoolVar(&c.deadOnly, "dead-only", false, "destroy through the provider only, for controllers whose API server is unreachable")
 }
 
 // Init implements Command.Init.
@@ -82,6 +95,7 @@ func (c *killCommand) Init(args []string) error {
 
 // Run implements Command.Run
 func (c *killCommand) Run(ctx *cmd.Context) error {
+	defer c.closeAnswers()
 	controllerName := c.ControllerName()
 	store := c.ClientStore()
 	controllerDetails, err := store.ControllerByName(controllerName)
@@ -89,10 +103,12 @@ func (c *killCommand) Run(ctx *cmd.Context) error {
 		return errors.Annotate(err, "cannot read controller info")
 	}
 
-	if !c.assumeYes {
-		if err = confirmDestruction(ctx, controllerName); err != nil {
-			return err
-		}
+	if err = c.confirm(ctx); err != nil {
+		return err
+	}
+
+	if c.deadOnly {
+		return c.killDeadController(ctx, store, controllerName)
 	}
 
 	// Attempt to connect to the API.
@@ -144,3 +160,26 @@ func (c *killCommand) Run(ctx *cmd.Context) error {
 
 	return environs.Destroy(controllerName, controllerEnviron, store)
 }
+
+// killDeadController destroys the controller through the provider, using
+// only the bootstrap config in the client store. It refuses to do so if
+// the controller's API server is reachable, since destroying through the
+// provider would skip the proper cleanup of hosted models.
+func (c *killCommand) killDeadController(ctx *cmd.Context, store jujuclient.ClientStore, controllerName string) error {
+	api, err := c.getControllerAPI()
+	if err == nil {
+		api.Close()
+		return errors.Errorf(
+			"controller %q API server is reachable, use destroy-controller instead of --dead-only",
+			controllerName,
+		)
+	}
+	logger.Debugf("unable to open api: %s", err)
+
+	controllerEnviron, err := c.getControllerEnviron(store, controllerName, nil)
+	if err != nil {
+		return errors.Annotate(err, "getting controller environ")
+	}
+	ctx.Infof("Destroying controller %q through provider.", controllerName)
+	return environs.Destroy(controllerName, controllerEnviron, store)
+}
@@ -77,6 +77,43 @@ func (s *KillSuite) TestKillWithAPIConnection(c *gc.C) {
 	checkControllerRemovedFromStore(c, "local.test1", s.store)
 }
 
+func (s *KillSuite) TestKillConfirmName(c *gc.C) {
+	_, err := s.runKillCommand(c, "local.test1", "--confirm", "local.test1")
+	c.Assert(err, jc.ErrorIsNil)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *KillSuite) TestKillConfirmNameMismatch(c *gc.C) {
+	_, err := s.runKillCommand(c, "local.test1", "--confirm", "test3")
+	c.Assert(err, gc.ErrorMatches, `controller destruction aborted: "test3" does not match controller name "local.test1"`)
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *KillSuite) TestKillDeadOnly(c *gc.C) {
+	s.apierror = errors.New("connection refused")
+	ctx, err := s.runKillCommand(c, "local.test1", "-y", "--dead-only")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stderr(ctx), jc.Contains, `Destroying controller "local.test1" through provider.`)
+	c.Assert(s.api.destroyAll, jc.IsFalse)
+	checkControllerRemovedFromStore(c, "local.test1", s.store)
+}
+
+func (s *KillSuite) TestKillDeadOnlyAPIReachable(c *gc.C) {
+	_, err := s.runKillCommand(c, "local.test1", "-y", "--dead-only")
+	c.Assert(err, gc.ErrorMatches, `controller "local.test1" API server is reachable, use destroy-controller instead of --dead-only`)
+	c.Assert(s.api.destroyAll, jc.IsFalse)
+	checkControllerExistsInStore(c, "local.test1", s.store)
+}
+
+func (s *KillSuite) TestKillDeadOnlyNeedsBootstrapConfig(c *gc.C) {
+	s.apierror = errors.New("connection refused")
+	_, err := s.runKillCommand(c, "test3", "-y", "--dead-only")
+	c.Assert(err, gc.ErrorMatches,
+		"getting controller environ: unable to get bootstrap information from client store or API",
+	)
+	checkControllerExistsInStore(c, "test3", s.store)
+}
+
 func (s *KillSuite) TestKillEnvironmentGetFailsWithoutAPIConnection(c *gc.C) {
 	s.apierror = errors.New("connection refused")
 	s.api.SetErrors(errors.NotFoundf(`controller "test3"`))
@@ -194,6 +231,7 @@ func (s *KillSuite) TestControllerStatus(c *gc.C) {
 			Life:               params.Dying,
 			HostedMachineCount: 2,
 			ServiceCount:       1,
+			VolumeCount:        1,
 			Owner:              owner.Canonical(),
 		}
 	}
@@ -203,6 +241,7 @@ func (s *KillSuite) TestControllerStatus(c *gc.C) {
 	c.Assert(ctrStatus.HostedModelCount, gc.Equals, 2)
 	c.Assert(ctrStatus.HostedMachineCount, gc.Equals, 6)
 	c.Assert(ctrStatus.ServiceCount, gc.Equals, 3)
+	c.Assert(ctrStatus.VolumeCount, gc.Equals, 3)
 	c.Assert(envsStatus, gc.HasLen, 2)
 
 	for i, expected := range []struct {
@@ -242,9 +281,10 @@ func (s *KillSuite) TestFmtControllerStatus(c *gc.C) {
 		3,
 		20,
 		8,
+		2,
 	}
 	out := controller.FmtCtrStatus(data)
-	c.Assert(out, gc.Equals, "Waiting on 3 models, 20 machines, 8 services")
+	c.Assert(out, gc.Equals, "Waiting on 3 models, 20 machines, 8 services, 2 volumes")
 }
 
 func (s *KillSuite) TestFmtEnvironStatus(c *gc.C) {
@@ -255,8 +295,27 @@ func (s *KillSuite) TestFmtEnvironStatus(c *gc.C) {
 		params.Dying,
 		8,
 		1,
+		0,
 	}
 
 	out := controller.FmtModelStatus(data)
 	c.Assert(out, gc.Equals, "\towner@local/envname (dying), 8 machines, 1 service")
 }
+
+func (s *KillSuite) TestFmtControllerStatusDelta(c *gc.C) {
+	prev := controller.CtrData{"uuid", params.Dying, 3, 20, 8, 2}
+	cur := controller.CtrData{"uuid", params.Dying, 3, 19, 6, 2}
+	c.Assert(controller.FmtCtrStatusDelta(prev, cur), gc.Equals, "Reclaimed 1 machine, 2 services since last check")
+	c.Assert(controller.FmtCtrStatusDelta(cur, cur), gc.Equals, "")
+
+	prev = controller.CtrData{"uuid", params.Dying, 3, 20, 8, 5}
+	cur = controller.CtrData{"uuid", params.Dying, 3, 20, 8, 4}
+	c.Assert(controller.FmtCtrStatusDelta(prev, cur), gc.Equals, "Reclaimed 1 volume since last check")
+}
+
+func (s *KillSuite) TestSameResourceCounts(c *gc.C) {
+	prev := controller.CtrData{"uuid", params.Dying, 3, 20, 8, 2}
+	c.Assert(controller.SameResourceCounts(prev, prev), jc.IsTrue)
+	cur := controller.CtrData{"uuid", params.Dying, 3, 20, 8, 1}
+	c.Assert(controller.SameResourceCounts(prev, cur), jc.IsFalse)
+}
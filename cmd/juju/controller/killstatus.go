@@ -5,6 +5,7 @@ package controller
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/juju/cmd"
@@ -19,6 +20,7 @@ type ctrData struct {
 	HostedModelCount   int
 	HostedMachineCount int
 	ServiceCount       int
+	VolumeCount        int
 }
 
 type modelData struct {
@@ -29,6 +31,7 @@ type modelData struct {
 
 	HostedMachineCount int
 	ServiceCount       int
+	VolumeCount        int
 }
 
 // newTimedStatusUpdater returns a function which waits a given period of time
@@ -85,6 +88,7 @@ func newData(api destroyControllerAPI, ctrUUID string) (ctrData, []modelData, er
 
 	hostedMachinesCount := ctrStatus.HostedMachineCount
 	servicesCount := ctrStatus.ServiceCount
+	volumesCount := ctrStatus.VolumeCount
 	var modelsData []modelData
 	var aliveModelCount int
 	for _, model := range hostedStatus {
@@ -98,11 +102,13 @@ func newData(api destroyControllerAPI, ctrUUID string) (ctrData, []modelData, er
 			model.Life,
 			model.HostedMachineCount,
 			model.ServiceCount,
+			model.VolumeCount,
 		})
 
 		aliveModelCount++
 		hostedMachinesCount += model.HostedMachineCount
 		servicesCount += model.ServiceCount
+		volumesCount += model.VolumeCount
 	}
 
 	ctrFinalStatus := ctrData{
@@ -111,6 +117,7 @@ func newData(api destroyControllerAPI, ctrUUID string) (ctrData, []modelData, er
 		aliveModelCount,
 		hostedMachinesCount,
 		servicesCount,
+		volumesCount,
 	}
 
 	return ctrFinalStatus, modelsData, nil
@@ -142,8 +149,14 @@ func s(n int) string {
 }
 
 func fmtCtrStatus(data ctrData) string {
+	return "Waiting on " + fmtCtrResources(data)
+}
+
+// fmtCtrResources lists the number of hosted models of a controller,
+// and of the machines, services and volumes in them.
+func fmtCtrResources(data ctrData) string {
 	modelNo := data.HostedModelCount
-	out := fmt.Sprintf("Waiting on %d model%s", modelNo, s(modelNo))
+	out := fmt.Sprintf("%d model%s", modelNo, s(modelNo))
 
 	if machineNo := data.HostedMachineCount; machineNo > 0 {
 		out += fmt.Sprintf(", %d machine%s", machineNo, s(machineNo))
@@ -153,6 +166,10 @@ func fmtCtrStatus(data ctrData) string {
 		out += fmt.Sprintf(", %d service%s", serviceNo, s(serviceNo))
 	}
 
+	if volumeNo := data.VolumeCount; volumeNo > 0 {
+		out += fmt.Sprintf(", %d volume%s", volumeNo, s(volumeNo))
+	}
+
 	return out
 }
 
@@ -167,5 +184,41 @@ func fmtModelStatus(data modelData) string {
 		out += fmt.Sprintf(", %d service%s", serviceNo, s(serviceNo))
 	}
 
+	if volumeNo := data.VolumeCount; volumeNo > 0 {
+		out += fmt.Sprintf(", %d volume%s", volumeNo, s(volumeNo))
+	}
+
 	return out
 }
+
+// fmtCtrStatusDelta describes the resources reclaimed between two
+// successive controller status checks. An empty string is returned
+// if nothing changed.
+func fmtCtrStatusDelta(prev, cur ctrData) string {
+	var parts []string
+	if n := prev.HostedModelCount - cur.HostedModelCount; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d model%s", n, s(n)))
+	}
+	if n := prev.HostedMachineCount - cur.HostedMachineCount; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d machine%s", n, s(n)))
+	}
+	if n := prev.ServiceCount - cur.ServiceCount; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d service%s", n, s(n)))
+	}
+	if n := prev.VolumeCount - cur.VolumeCount; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d volume%s", n, s(n)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "Reclaimed " + strings.Join(parts, ", ") + " since last check"
+}
+
+// sameResourceCounts reports whether two controller status checks
+// found the same number of outstanding resources.
+func sameResourceCounts(a, b ctrData) bool {
+	return a.HostedModelCount == b.HostedModelCount &&
+		a.HostedMachineCount == b.HostedMachineCount &&
+		a.ServiceCount == b.ServiceCount &&
+		a.VolumeCount == b.VolumeCount
+}
@@ -162,9 +162,9 @@ func (s *BundleDeployCharmStoreSuite) TestDeployBundleEndpointBindingsSpaceMissi
 }
 
 func (s *BundleDeployCharmStoreSuite) TestDeployBundleEndpointBindingsSuccess(c *gc.C) {
-	_, err := s.State.AddSpace("db", "", nil, false)
+	_, err := s.State.AddSpace("db", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("public", "", nil, false)
+	_, err = s.State.AddSpace("public", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	testcharms.UploadCharm(c, s.client, "xenial/mysql-42", "mysql")
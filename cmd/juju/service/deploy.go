@@ -591,11 +591,18 @@ func handleResources(c APICmd, resources map[string]string, serviceName string,
 		return nil, errors.Trace(err)
 	}
 
-	ids, err := resourceadapters.DeployResources(serviceName, chID, csMac, resources, metaResources, api)
+	results, err := resourceadapters.DeployResourcesWithResults(serviceName, chID, csMac, resources, nil, metaResources, api)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
+	ids := make(map[string]string)
+	for name, result := range results {
+		ids[name] = result.PendingID
+		if result.Origin == charmresource.OriginStore && result.Revision >= 0 {
+			logger.Infof("using charm store revision %d of resource %q", result.Revision, name)
+		}
+	}
 	return ids, nil
 }
 
@@ -1031,9 +1031,9 @@ func (s *DeploySuite) TestDeployFlags(c *gc.C) {
 }
 
 func (s *DeployCharmStoreSuite) TestDeployCharmWithSomeEndpointBindingsSpecifiedSuccess(c *gc.C) {
-	_, err := s.State.AddSpace("db", "", nil, false)
+	_, err := s.State.AddSpace("db", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("public", "", nil, false)
+	_, err = s.State.AddSpace("public", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	testcharms.UploadCharm(c, s.client, "cs:quantal/wordpress-extra-bindings-1", "wordpress-extra-bindings")
@@ -13,6 +13,8 @@ import (
 var (
 	ConvertToVolumeInfo     = convertToVolumeInfo
 	ConvertToFilesystemInfo = convertToFilesystemInfo
+
+	FormatFilesystemAttachmentsSummary = formatFilesystemAttachmentsSummary
 )
 
 func NewPoolListCommandForTest(api PoolListAPI, store jujuclient.ClientStore) cmd.Command {
@@ -9,9 +9,11 @@ import (
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	"github.com/juju/utils/set"
 
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/common"
+	"github.com/juju/juju/status"
 )
 
 // FilesystemCommandBase is a helper base structure for filesystem commands.
@@ -27,6 +29,10 @@ type FilesystemInfo struct {
 	// Volume is the ID of the volume that the filesystem is backed by, if any.
 	Volume string
 
+	// BackingVolume holds details of the volume that the filesystem is
+	// backed by, if any, when requested with --with-volumes.
+	BackingVolume *BackingVolumeInfo `yaml:"backing-volume,omitempty" json:"backing-volume,omitempty"`
+
 	// Storage is the ID of the storage instance that the filesystem is
 	// assigned to, if any.
 	Storage string
@@ -41,6 +47,19 @@ type FilesystemInfo struct {
 	Status EntityStatus `yaml:"status,omitempty" json:"status,omitempty"`
 }
 
+// BackingVolumeInfo defines the serialization behaviour for the volume
+// backing a filesystem.
+type BackingVolumeInfo struct {
+	// from params.Volume. This is provider-supplied unique volume id.
+	ProviderVolumeId string `yaml:"provider-id,omitempty" json:"provider-id,omitempty"`
+
+	// from params.Volume
+	Size uint64 `yaml:"size" json:"size"`
+
+	// from params.Volume
+	Status EntityStatus `yaml:"status,omitempty" json:"status,omitempty"`
+}
+
 type FilesystemAttachments struct {
 	Machines map[string]MachineFilesystemAttachment `yaml:"machines,omitempty" json:"machines,omitempty"`
 	Units    map[string]UnitStorageAttachment       `yaml:"units,omitempty" json:"units,omitempty"`
@@ -51,6 +70,25 @@ type MachineFilesystemAttachment struct {
 	ReadOnly   bool   `yaml:"read-only" json:"read-only"`
 }
 
+// AttachedFilesystemInfo defines the serialization behaviour for a
+// filesystem listed under the machine it is attached to.
+type AttachedFilesystemInfo struct {
+	ProviderFilesystemId string       `yaml:"provider-id,omitempty" json:"provider-id,omitempty"`
+	Volume               string       `yaml:"volume,omitempty" json:"volume,omitempty"`
+	Storage              string       `yaml:"storage,omitempty" json:"storage,omitempty"`
+	Size                 uint64       `yaml:"size" json:"size"`
+	Status               EntityStatus `yaml:"status,omitempty" json:"status,omitempty"`
+
+	// MountPoint and ReadOnly are from the filesystem's
+	// MachineFilesystemAttachment for the machine.
+	MountPoint string `yaml:"mount-point,omitempty" json:"mount-point,omitempty"`
+	ReadOnly   bool   `yaml:"read-only,omitempty" json:"read-only,omitempty"`
+}
+
+// unattachedGroup is the machine id under which filesystems that are
+// not attached to any machine are listed with --by-machine.
+const unattachedGroup = "unattached"
+
 // generateListFilesystemOutput returns a map filesystem IDs to filesystem info
 func (c *listCommand) generateListFilesystemsOutput(ctx *cmd.Context, api StorageListAPI) (output interface{}, err error) {
 
@@ -72,25 +110,187 @@ func (c *listCommand) generateListFilesystemsOutput(ctx *cmd.Context, api Storag
 	if len(valid) == 0 {
 		return nil, nil
 	}
-	info, err := convertToFilesystemInfo(valid)
+	info, err := convertToFilesystemInfo(valid, c.isoTime)
 	if err != nil {
 		return nil, err
 	}
+	if c.withVolumes {
+		if err := addBackingVolumeInfo(ctx, api, info); err != nil {
+			return nil, err
+		}
+	}
+	info = filterFilesystemInfoByStatus(info, c.statuses)
+	if c.orphaned {
+		info = filterOrphanedFilesystemInfo(info)
+	}
+	if c.writableOnly {
+		info = filterWritableFilesystemInfo(info)
+	}
+	if c.exitStatus {
+		statuses := c.exitStatuses
+		if len(statuses) == 0 {
+			statuses = []string{string(status.StatusError)}
+		}
+		c.exitStatusMatched = len(filterFilesystemInfoByStatus(info, statuses)) > 0
+	}
+	if len(info) == 0 {
+		return nil, nil
+	}
 	switch c.out.Name() {
 	case "yaml", "json":
-		output = map[string]map[string]FilesystemInfo{"filesystems": info}
+		if c.byMachine {
+			output = map[string]map[string]map[string]AttachedFilesystemInfo{
+				"machines": groupFilesystemInfoByMachine(info),
+			}
+		} else {
+			output = map[string]map[string]FilesystemInfo{"filesystems": info}
+		}
 	default:
+		// The tabular format is already ordered by machine.
 		output = info
 	}
 
 	return output, nil
 }
 
+// groupFilesystemInfoByMachine returns a map of machine IDs to the
+// filesystems attached to each machine, keyed by filesystem ID.
+// Filesystems not attached to any machine are grouped under
+// unattachedGroup.
+func groupFilesystemInfoByMachine(info map[string]FilesystemInfo) map[string]map[string]AttachedFilesystemInfo {
+	result := make(map[string]map[string]AttachedFilesystemInfo)
+	add := func(machineId, filesystemId string, one AttachedFilesystemInfo) {
+		group, ok := result[machineId]
+		if !ok {
+			group = make(map[string]AttachedFilesystemInfo)
+			result[machineId] = group
+		}
+		group[filesystemId] = one
+	}
+	for filesystemId, one := range info {
+		attached := AttachedFilesystemInfo{
+			ProviderFilesystemId: one.ProviderFilesystemId,
+			Volume:               one.Volume,
+			Storage:              one.Storage,
+			Size:                 one.Size,
+			Status:               one.Status,
+		}
+		if one.Attachments == nil || len(one.Attachments.Machines) == 0 {
+			add(unattachedGroup, filesystemId, attached)
+			continue
+		}
+		for machineId, attachment := range one.Attachments.Machines {
+			attached := attached
+			attached.MountPoint = attachment.MountPoint
+			attached.ReadOnly = attachment.ReadOnly
+			add(machineId, filesystemId, attached)
+		}
+	}
+	return result
+}
+
+// addBackingVolumeInfo sets the BackingVolume of each filesystem in info
+// that is backed by a volume, using the volume details from the API.
+func addBackingVolumeInfo(ctx *cmd.Context, api StorageListAPI, info map[string]FilesystemInfo) error {
+	backed := false
+	for _, one := range info {
+		if one.Volume != "" {
+			backed = true
+			break
+		}
+	}
+	if !backed {
+		return nil
+	}
+
+	results, err := api.ListVolumes(nil)
+	if err != nil {
+		return err
+	}
+	var valid []params.VolumeDetails
+	for _, result := range results {
+		if result.Error == nil {
+			valid = append(valid, result.Result...)
+			continue
+		}
+		// display individual error
+		fmt.Fprintf(ctx.Stderr, "%v\n", result.Error)
+	}
+	volumes, err := convertToVolumeInfo(valid)
+	if err != nil {
+		return err
+	}
+	for id, one := range info {
+		volume, ok := volumes[one.Volume]
+		if !ok {
+			continue
+		}
+		one.BackingVolume = &BackingVolumeInfo{
+			ProviderVolumeId: volume.ProviderVolumeId,
+			Size:             volume.Size,
+			Status:           volume.Status,
+		}
+		info[id] = one
+	}
+	return nil
+}
+
+// filterFilesystemInfoByStatus returns the filesystems in info whose
+// current status is one of statuses. If no statuses are specified, info
+// is returned unchanged.
+func filterFilesystemInfoByStatus(info map[string]FilesystemInfo, statuses []string) map[string]FilesystemInfo {
+	if len(statuses) == 0 {
+		return info
+	}
+	wanted := set.NewStrings(statuses...)
+	result := make(map[string]FilesystemInfo)
+	for id, one := range info {
+		if wanted.Contains(string(one.Status.Current)) {
+			result[id] = one
+		}
+	}
+	return result
+}
+
+// filterOrphanedFilesystemInfo returns the filesystems in info that are
+// neither assigned to a storage instance nor attached to anything.
+func filterOrphanedFilesystemInfo(info map[string]FilesystemInfo) map[string]FilesystemInfo {
+	result := make(map[string]FilesystemInfo)
+	for id, one := range info {
+		if one.Storage == "" && one.Attachments == nil {
+			result[id] = one
+		}
+	}
+	return result
+}
+
+// filterWritableFilesystemInfo returns the filesystems in info that are
+// not attached read-only to any machine.
+func filterWritableFilesystemInfo(info map[string]FilesystemInfo) map[string]FilesystemInfo {
+	result := make(map[string]FilesystemInfo)
+	for id, one := range info {
+		readOnly := false
+		if one.Attachments != nil {
+			for _, attachment := range one.Attachments.Machines {
+				if attachment.ReadOnly {
+					readOnly = true
+					break
+				}
+			}
+		}
+		if !readOnly {
+			result[id] = one
+		}
+	}
+	return result
+}
+
 // convertToFilesystemInfo returns a map of filesystem IDs to filesystem info.
-func convertToFilesystemInfo(all []params.FilesystemDetails) (map[string]FilesystemInfo, error) {
+// If isoTime is true, status timestamps are formatted as UTC ISO time.
+func convertToFilesystemInfo(all []params.FilesystemDetails, isoTime bool) (map[string]FilesystemInfo, error) {
 	result := make(map[string]FilesystemInfo)
 	for _, one := range all {
-		filesystemTag, info, err := createFilesystemInfo(one)
+		filesystemTag, info, err := createFilesystemInfo(one, isoTime)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -99,7 +299,7 @@ func convertToFilesystemInfo(all []params.FilesystemDetails) (map[string]Filesys
 	return result, nil
 }
 
-func createFilesystemInfo(details params.FilesystemDetails) (names.FilesystemTag, FilesystemInfo, error) {
+func createFilesystemInfo(details params.FilesystemDetails, isoTime bool) (names.FilesystemTag, FilesystemInfo, error) {
 	filesystemTag, err := names.ParseFilesystemTag(details.FilesystemTag)
 	if err != nil {
 		return names.FilesystemTag{}, FilesystemInfo{}, errors.Trace(err)
@@ -111,8 +311,7 @@ func createFilesystemInfo(details params.FilesystemDetails) (names.FilesystemTag
 	info.Status = EntityStatus{
 		details.Status.Status,
 		details.Status.Info,
-		// TODO(axw) we should support formatting as ISO time
-		common.FormatTime(details.Status.Since, false),
+		common.FormatTime(details.Status.Since, isoTime),
 	}
 
 	if details.VolumeTag != "" {
@@ -123,18 +322,11 @@ func createFilesystemInfo(details params.FilesystemDetails) (names.FilesystemTag
 		info.Volume = volumeId
 	}
 
-	if len(details.MachineAttachments) > 0 {
-		machineAttachments := make(map[string]MachineFilesystemAttachment)
-		for machineTag, attachment := range details.MachineAttachments {
-			machineId, err := idFromTag(machineTag)
-			if err != nil {
-				return names.FilesystemTag{}, FilesystemInfo{}, errors.Trace(err)
-			}
-			machineAttachments[machineId] = MachineFilesystemAttachment{
-				attachment.MountPoint,
-				attachment.ReadOnly,
-			}
-		}
+	machineAttachments, err := machineFilesystemAttachments(details.MachineAttachments)
+	if err != nil {
+		return names.FilesystemTag{}, FilesystemInfo{}, errors.Trace(err)
+	}
+	if machineAttachments != nil {
 		info.Attachments = &FilesystemAttachments{
 			Machines: machineAttachments,
 		}
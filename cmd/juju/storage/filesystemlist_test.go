@@ -5,6 +5,7 @@ package storage_test
 
 import (
 	"encoding/json"
+	"sort"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -88,13 +89,13 @@ func (s *ListSuite) TestFilesystemListWithErrorResults(c *gc.C) {
 }
 
 var expectedFilesystemListTabular = `
-MACHINE  UNIT         STORAGE      ID   VOLUME  PROVIDER-ID                       MOUNTPOINT  SIZE    STATE      MESSAGE
-0        abc/0        db-dir/1001  0/0  0/1     provider-supplied-filesystem-0-0  /mnt/fuji   512MiB  attached   
-0        transcode/0  shared-fs/0  4            provider-supplied-filesystem-4    /mnt/doom   1.0GiB  attached   
-0                                  1            provider-supplied-filesystem-1                2.0GiB  attaching  failed to attach, will retry
-1        transcode/1  shared-fs/0  4            provider-supplied-filesystem-4    /mnt/huang  1.0GiB  attached   
-1                                  2            provider-supplied-filesystem-2    /mnt/zion   3.0MiB  attached   
-1                                  3                                                          42MiB   pending    
+MACHINE  UNIT         STORAGE      ID   VOLUME  PROVIDER-ID                       MOUNTPOINT       ATTACHMENTS          SIZE    STATE      MESSAGE
+0        abc/0        db-dir/1001  0/0  0/1     provider-supplied-filesystem-0-0  /mnt/fuji        1 machine, 1 unit    512MiB  attached   
+0        transcode/0  shared-fs/0  4            provider-supplied-filesystem-4    /mnt/doom (ro)   2 machines, 2 units  1.0GiB  attached   
+0                                  1            provider-supplied-filesystem-1                     1 machine            2.0GiB  attaching  failed to attach, will retry
+1        transcode/1  shared-fs/0  4            provider-supplied-filesystem-4    /mnt/huang (ro)  2 machines, 2 units  1.0GiB  attached   
+1                                  2            provider-supplied-filesystem-2    /mnt/zion        1 machine            3.0MiB  attached   
+1                                  3                                                               1 machine            42MiB   pending    
 
 `[1:]
 
@@ -114,6 +115,327 @@ func (s *ListSuite) TestFilesystemListTabular(c *gc.C) {
 	s.assertValidFilesystemList(c, []string{}, expectedFilesystemListTabular)
 }
 
+func (s *ListSuite) TestFilesystemListStatusFilterTabular(c *gc.C) {
+	s.assertValidFilesystemList(c, []string{"--status", "attaching", "--status", "pending"}, `
+MACHINE  UNIT  STORAGE  ID  VOLUME  PROVIDER-ID                     MOUNTPOINT  ATTACHMENTS  SIZE    STATE      MESSAGE
+0                       1           provider-supplied-filesystem-1              1 machine    2.0GiB  attaching  failed to attach, will retry
+1                       3                                                       1 machine    42MiB   pending    
+
+`[1:])
+}
+
+func (s *ListSuite) TestFilesystemListWritableOnly(c *gc.C) {
+	context, err := s.runFilesystemList(c, "--format", "yaml", "--writable-only")
+	c.Assert(err, jc.ErrorIsNil)
+
+	var result struct {
+		Filesystems map[string]storage.FilesystemInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	ids := make([]string, 0, len(result.Filesystems))
+	for id := range result.Filesystems {
+		ids = append(ids, id)
+	}
+	c.Assert(ids, jc.SameContents, []string{"0/0", "1", "2", "3"})
+}
+
+func (s *ListSuite) TestFilesystemListWritableOnlyRequiresFilesystem(c *gc.C) {
+	_, err := testing.RunCommand(c, storage.NewListCommandForTest(s.mockAPI, s.store), "--writable-only")
+	c.Assert(err, gc.ErrorMatches, "--writable-only can only be used with --filesystem")
+}
+
+func (s *ListSuite) TestFilesystemListStatusFilterYaml(c *gc.C) {
+	context, err := s.runFilesystemList(c, "--format", "yaml", "--status", "pending")
+	c.Assert(err, jc.ErrorIsNil)
+
+	var result struct {
+		Filesystems map[string]storage.FilesystemInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected := s.expect(c, nil)
+	c.Assert(result.Filesystems, jc.DeepEquals, map[string]storage.FilesystemInfo{
+		"3": expected["3"],
+	})
+}
+
+func (s *ListSuite) TestFilesystemListStatusFilterNoMatch(c *gc.C) {
+	s.assertValidFilesystemList(c, []string{"--format", "json", "--status", "error"}, "")
+}
+
+func (s *ListSuite) TestFilesystemListUTC(c *gc.C) {
+	context, err := s.runFilesystemList(c, "--format", "yaml", "--utc")
+	c.Assert(err, jc.ErrorIsNil)
+
+	var result struct {
+		Filesystems map[string]storage.FilesystemInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Filesystems, gc.HasLen, 5)
+	for _, info := range result.Filesystems {
+		c.Check(info.Status.Since, gc.Equals, "0001-01-01 00:00:00Z")
+	}
+}
+
+func (s *ListSuite) TestFilesystemAttachmentsSummary(c *gc.C) {
+	for _, test := range []struct {
+		attachments *storage.FilesystemAttachments
+		expected    string
+	}{{
+		nil, "unattached",
+	}, {
+		&storage.FilesystemAttachments{}, "unattached",
+	}, {
+		&storage.FilesystemAttachments{
+			Machines: map[string]storage.MachineFilesystemAttachment{"0": {}},
+		}, "1 machine",
+	}, {
+		&storage.FilesystemAttachments{
+			Machines: map[string]storage.MachineFilesystemAttachment{"0": {}, "1": {}, "2": {}},
+			Units:    map[string]storage.UnitStorageAttachment{"a/0": {}, "a/1": {}},
+		}, "3 machines, 2 units",
+	}} {
+		c.Check(storage.FormatFilesystemAttachmentsSummary(test.attachments), gc.Equals, test.expected)
+	}
+}
+
+func (s *ListSuite) TestFilesystemListSortBySize(c *gc.C) {
+	s.assertValidFilesystemList(c, []string{"--sort", "size"}, `
+MACHINE  UNIT         STORAGE      ID   VOLUME  PROVIDER-ID                       MOUNTPOINT       ATTACHMENTS          SIZE    STATE      MESSAGE
+1                                  2            provider-supplied-filesystem-2    /mnt/zion        1 machine            3.0MiB  attached   
+1                                  3                                                               1 machine            42MiB   pending    
+0        abc/0        db-dir/1001  0/0  0/1     provider-supplied-filesystem-0-0  /mnt/fuji        1 machine, 1 unit    512MiB  attached   
+0        transcode/0  shared-fs/0  4            provider-supplied-filesystem-4    /mnt/doom (ro)   2 machines, 2 units  1.0GiB  attached   
+1        transcode/1  shared-fs/0  4            provider-supplied-filesystem-4    /mnt/huang (ro)  2 machines, 2 units  1.0GiB  attached   
+0                                  1            provider-supplied-filesystem-1                     1 machine            2.0GiB  attaching  failed to attach, will retry
+
+`[1:])
+}
+
+func (s *ListSuite) TestFilesystemListCSV(c *gc.C) {
+	s.assertValidFilesystemList(c, []string{"--format", "csv"}, `
+id,size,status,volume,storage,attachments
+0/0,512,attached,0/1,db-dir/1001,2
+1,2048,attaching,,,1
+2,3,attached,,,1
+3,42,pending,,,1
+4,1024,attached,,shared-fs/0,4
+
+`[1:])
+}
+
+func (s *ListSuite) TestFilesystemListCSVSortBySize(c *gc.C) {
+	s.assertValidFilesystemList(c, []string{"--format", "csv", "--sort", "size"}, `
+id,size,status,volume,storage,attachments
+2,3,attached,,,1
+3,42,pending,,,1
+0/0,512,attached,0/1,db-dir/1001,2
+4,1024,attached,,shared-fs/0,4
+1,2048,attaching,,,1
+
+`[1:])
+}
+
+func (s *ListSuite) TestFilesystemOnlyFlagsRequireFilesystem(c *gc.C) {
+	for _, args := range [][]string{
+		{"--status", "pending"},
+		{"--orphaned"},
+		{"--sort", "size"},
+		{"--by-machine"},
+		{"--utc"},
+	} {
+		_, err := testing.RunCommand(c, storage.NewListCommandForTest(s.mockAPI, s.store), args...)
+		c.Check(err, gc.ErrorMatches, args[0]+" can only be used with --filesystem")
+	}
+}
+
+func (s *ListSuite) TestListCSVRequiresFilesystem(c *gc.C) {
+	_, err := testing.RunCommand(c, storage.NewListCommandForTest(s.mockAPI, s.store), "--format", "csv")
+	c.Assert(err, gc.ErrorMatches, "--format csv can only be used with --filesystem")
+}
+
+func (s *ListSuite) TestMachineAttachmentsConvertedConsistently(c *gc.C) {
+	filesystems, err := storage.ConvertToFilesystemInfo([]params.FilesystemDetails{{
+		FilesystemTag: "filesystem-0",
+		MachineAttachments: map[string]params.FilesystemAttachmentInfo{
+			"machine-0": {MountPoint: "/mnt/ro", ReadOnly: true},
+			"machine-1": {MountPoint: "/mnt/rw"},
+		},
+	}}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(filesystems["0"].Attachments.Machines, jc.DeepEquals, map[string]storage.MachineFilesystemAttachment{
+		"0": {MountPoint: "/mnt/ro", ReadOnly: true},
+		"1": {MountPoint: "/mnt/rw"},
+	})
+
+	volumes, err := storage.ConvertToVolumeInfo([]params.VolumeDetails{{
+		VolumeTag: "volume-0",
+		MachineAttachments: map[string]params.VolumeAttachmentInfo{
+			"machine-0": {DeviceName: "xvdf", ReadOnly: true},
+			"machine-1": {DeviceName: "xvdg"},
+		},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(volumes["0"].Attachments.Machines, jc.DeepEquals, map[string]storage.MachineVolumeAttachment{
+		"0": {DeviceName: "xvdf", ReadOnly: true},
+		"1": {DeviceName: "xvdg"},
+	})
+
+	_, err = storage.ConvertToFilesystemInfo([]params.FilesystemDetails{{
+		FilesystemTag:      "filesystem-0",
+		MachineAttachments: map[string]params.FilesystemAttachmentInfo{"bad": {}},
+	}}, false)
+	c.Check(err, gc.ErrorMatches, `machine attachment: invalid tag .*`)
+}
+
+func (s *ListSuite) TestFilesystemListInvalidSortKey(c *gc.C) {
+	_, err := s.runFilesystemList(c, "--sort", "colour")
+	c.Assert(err, gc.ErrorMatches, `invalid sort key "colour", expected one of id, size, status, storage`)
+}
+
+func (s *ListSuite) TestFilesystemListOrphaned(c *gc.C) {
+	s.mockAPI.listFilesystems = func([]string) ([]params.FilesystemDetailsListResult, error) {
+		results, _ := mockListAPI{}.ListFilesystems(nil)
+		// filesystem 5 is neither attached nor assigned to storage.
+		results[0].Result = append(results[0].Result, params.FilesystemDetails{
+			FilesystemTag: "filesystem-5",
+			Info: params.FilesystemInfo{
+				FilesystemId: "provider-supplied-filesystem-5",
+				Size:         1024,
+			},
+			Status: createTestStatus(status.StatusAttached, ""),
+		})
+		return results, nil
+	}
+
+	context, err := s.runFilesystemList(c, "--format", "yaml", "--orphaned")
+	c.Assert(err, jc.ErrorIsNil)
+	var result struct {
+		Filesystems map[string]storage.FilesystemInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.Filesystems, jc.DeepEquals, map[string]storage.FilesystemInfo{
+		"5": s.expect(c, nil)["5"],
+	})
+
+	// The orphaned filter composes with the status filter.
+	s.assertValidFilesystemList(c, []string{"--format", "yaml", "--orphaned", "--status", "pending"}, "")
+}
+
+func (s *ListSuite) TestFilesystemListByMachine(c *gc.C) {
+	s.mockAPI.listFilesystems = func([]string) ([]params.FilesystemDetailsListResult, error) {
+		results, _ := mockListAPI{}.ListFilesystems(nil)
+		results[0].Result = append(results[0].Result, params.FilesystemDetails{
+			FilesystemTag: "filesystem-5",
+			Info:          params.FilesystemInfo{Size: 1024},
+			Status:        createTestStatus(status.StatusPending, ""),
+		})
+		return results, nil
+	}
+
+	context, err := s.runFilesystemList(c, "--format", "yaml", "--by-machine")
+	c.Assert(err, jc.ErrorIsNil)
+	var result struct {
+		Machines map[string]map[string]storage.AttachedFilesystemInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ids := func(group map[string]storage.AttachedFilesystemInfo) []string {
+		var ids []string
+		for id := range group {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	c.Assert(result.Machines, gc.HasLen, 3)
+	c.Check(ids(result.Machines["0"]), jc.DeepEquals, []string{"0/0", "1", "4"})
+	c.Check(ids(result.Machines["1"]), jc.DeepEquals, []string{"2", "3", "4"})
+	c.Check(ids(result.Machines["unattached"]), jc.DeepEquals, []string{"5"})
+
+	fs4 := result.Machines["1"]["4"]
+	c.Check(fs4.MountPoint, gc.Equals, "/mnt/huang")
+	c.Check(fs4.ReadOnly, jc.IsTrue)
+	c.Check(fs4.Storage, gc.Equals, "shared-fs/0")
+	c.Check(fs4.ProviderFilesystemId, gc.Equals, "provider-supplied-filesystem-4")
+	c.Check(result.Machines["0"]["4"].MountPoint, gc.Equals, "/mnt/doom")
+	c.Check(result.Machines["unattached"]["5"].MountPoint, gc.Equals, "")
+}
+
+func (s *ListSuite) TestFilesystemListExitStatus(c *gc.C) {
+	// None of the filesystems have an error status.
+	_, err := s.runFilesystemList(c, "--exit-status")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.mockAPI.listFilesystems = func([]string) ([]params.FilesystemDetailsListResult, error) {
+		results, _ := mockListAPI{}.ListFilesystems(nil)
+		results[0].Result[1].Status = createTestStatus(status.StatusError, "boom")
+		return results, nil
+	}
+	context, err := s.runFilesystemList(c, "--exit-status")
+	c.Assert(err, gc.FitsTypeOf, (*cmd.RcPassthroughError)(nil))
+	c.Assert(err.(*cmd.RcPassthroughError).Code, gc.Equals, 2)
+	// The output is written regardless.
+	c.Assert(testing.Stdout(context), jc.Contains, "boom")
+}
+
+func (s *ListSuite) TestFilesystemListExitStatusOn(c *gc.C) {
+	_, err := s.runFilesystemList(c, "--exit-status", "--exit-status-on", "pending")
+	c.Assert(err, gc.FitsTypeOf, (*cmd.RcPassthroughError)(nil))
+
+	_, err = s.runFilesystemList(c, "--exit-status", "--exit-status-on", "destroying")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ListSuite) TestFilesystemListExitStatusInvalid(c *gc.C) {
+	_, err := testing.RunCommand(c, storage.NewListCommandForTest(s.mockAPI, s.store), "--exit-status")
+	c.Assert(err, gc.ErrorMatches, "--exit-status can only be used with --filesystem")
+	_, err = s.runFilesystemList(c, "--exit-status-on", "error")
+	c.Assert(err, gc.ErrorMatches, "--exit-status-on can only be used with --exit-status")
+}
+
+func (s *ListSuite) TestFilesystemListWithVolumes(c *gc.C) {
+	s.mockAPI.listVolumes = func(machines []string) ([]params.VolumeDetailsListResult, error) {
+		c.Check(machines, gc.HasLen, 0)
+		return []params.VolumeDetailsListResult{{Result: []params.VolumeDetails{{
+			VolumeTag: "volume-0-1",
+			Info: params.VolumeInfo{
+				VolumeId: "provider-supplied-volume-0-1",
+				Size:     1024,
+			},
+			Status: createTestStatus(status.StatusAttached, ""),
+		}}}}, nil
+	}
+
+	context, err := s.runFilesystemList(c, "--format", "yaml", "--with-volumes")
+	c.Assert(err, jc.ErrorIsNil)
+	var result struct {
+		Filesystems map[string]storage.FilesystemInfo
+	}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(context)), &result)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Assert(result.Filesystems["0/0"].BackingVolume, gc.NotNil)
+	backing := *result.Filesystems["0/0"].BackingVolume
+	c.Check(backing.ProviderVolumeId, gc.Equals, "provider-supplied-volume-0-1")
+	c.Check(backing.Size, gc.Equals, uint64(1024))
+	c.Check(backing.Status.Current, gc.Equals, status.StatusAttached)
+	// Filesystems not backed by a volume are unchanged.
+	c.Check(result.Filesystems["1"].BackingVolume, gc.IsNil)
+
+	// Without the flag, no volumes are fetched.
+	s.mockAPI.listVolumes = func([]string) ([]params.VolumeDetailsListResult, error) {
+		c.Fatalf("unexpected call to ListVolumes")
+		return nil, nil
+	}
+	s.assertUnmarshalledOutput(c, goyaml.Unmarshal, "", "--format", "yaml")
+}
+
 func (s *ListSuite) assertUnmarshalledOutput(c *gc.C, unmarshal unmarshaller, expectedErr string, args ...string) {
 	context, err := s.runFilesystemList(c, args...)
 	c.Assert(err, jc.ErrorIsNil)
@@ -143,7 +465,7 @@ func (s *ListSuite) expect(c *gc.C, machines []string) map[string]storage.Filesy
 			valid = append(valid, result.Result...)
 		}
 	}
-	result, err := storage.ConvertToFilesystemInfo(valid)
+	result, err := storage.ConvertToFilesystemInfo(valid, false)
 	c.Assert(err, jc.ErrorIsNil)
 	return result
 }
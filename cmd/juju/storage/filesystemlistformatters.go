@@ -5,6 +5,7 @@ package storage
 
 import (
 	"bytes"
+	"encoding/csv"
 	"fmt"
 	"sort"
 	"strings"
@@ -12,18 +13,25 @@ import (
 
 	"github.com/dustin/go-humanize"
 	"github.com/juju/errors"
+	"github.com/juju/utils/set"
 )
 
-// formatFilesystemListTabular returns a tabular summary of filesystem instances.
-func formatFilesystemListTabular(value interface{}) ([]byte, error) {
+// filesystemSortKeys holds the valid values for the list command's
+// --sort flag.
+var filesystemSortKeys = set.NewStrings("id", "size", "status", "storage")
+
+// formatFilesystemListTabular returns a tabular summary of filesystem
+// instances. If sortBy is non-empty, rows are ordered by that key,
+// otherwise they are ordered by machine.
+func formatFilesystemListTabular(value interface{}, sortBy string) ([]byte, error) {
 	infos, ok := value.(map[string]FilesystemInfo)
 	if !ok {
 		return nil, errors.Errorf("expected value of type %T, got %T", infos, value)
 	}
-	return formatFilesystemListTabularTyped(infos), nil
+	return formatFilesystemListTabularTyped(infos, sortBy), nil
 }
 
-func formatFilesystemListTabularTyped(infos map[string]FilesystemInfo) []byte {
+func formatFilesystemListTabularTyped(infos map[string]FilesystemInfo, sortBy string) []byte {
 	var out bytes.Buffer
 	const (
 		// To format things into columns.
@@ -38,7 +46,7 @@ func formatFilesystemListTabularTyped(infos map[string]FilesystemInfo) []byte {
 	print := func(values ...string) {
 		fmt.Fprintln(tw, strings.Join(values, "\t"))
 	}
-	print("MACHINE", "UNIT", "STORAGE", "ID", "VOLUME", "PROVIDER-ID", "MOUNTPOINT", "SIZE", "STATE", "MESSAGE")
+	print("MACHINE", "UNIT", "STORAGE", "ID", "VOLUME", "PROVIDER-ID", "MOUNTPOINT", "ATTACHMENTS", "SIZE", "STATE", "MESSAGE")
 
 	filesystemAttachmentInfos := make(filesystemAttachmentInfos, 0, len(infos))
 	for filesystemId, info := range infos {
@@ -70,16 +78,25 @@ func formatFilesystemListTabularTyped(infos map[string]FilesystemInfo) []byte {
 		}
 	}
 	sort.Sort(filesystemAttachmentInfos)
+	if sortBy != "" {
+		sort.Stable(filesystemAttachmentInfosBy{filesystemAttachmentInfos, sortBy})
+	}
 
 	for _, info := range filesystemAttachmentInfos {
 		var size string
 		if info.Size > 0 {
 			size = humanize.IBytes(info.Size * humanize.MiByte)
 		}
+		mountPoint := info.MountPoint
+		if info.ReadOnly {
+			// Flag read-only attachments, as applications
+			// may expect to be able to write to them.
+			mountPoint += " (ro)"
+		}
 		print(
 			info.MachineId, info.UnitId, info.Storage,
 			info.FilesystemId, info.Volume, info.ProviderFilesystemId,
-			info.MountPoint, size,
+			mountPoint, formatFilesystemAttachmentsSummary(info.Attachments), size,
 			string(info.Status.Current), info.Status.Message,
 		)
 	}
@@ -88,6 +105,81 @@ func formatFilesystemListTabularTyped(infos map[string]FilesystemInfo) []byte {
 	return out.Bytes()
 }
 
+// formatFilesystemListCSV returns a CSV summary of filesystem instances,
+// with one row per filesystem. Sizes are in MiB, and the attachments
+// column counts both machine and unit attachments, as the tabular
+// summary does. If sortBy is non-empty, rows are ordered by that key,
+// otherwise they are ordered by id.
+func formatFilesystemListCSV(value interface{}, sortBy string) ([]byte, error) {
+	infos, ok := value.(map[string]FilesystemInfo)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", infos, value)
+	}
+
+	rows := make(filesystemAttachmentInfos, 0, len(infos))
+	for filesystemId, info := range infos {
+		rows = append(rows, filesystemAttachmentInfo{
+			FilesystemId:   filesystemId,
+			FilesystemInfo: info,
+		})
+	}
+	if sortBy == "" {
+		sortBy = "id"
+	}
+	sort.Stable(filesystemAttachmentInfosBy{rows, sortBy})
+
+	var out bytes.Buffer
+	w := csv.NewWriter(&out)
+	w.Write([]string{"id", "size", "status", "volume", "storage", "attachments"})
+	for _, info := range rows {
+		var attachments int
+		if info.Attachments != nil {
+			attachments = len(info.Attachments.Machines) + len(info.Attachments.Units)
+		}
+		w.Write([]string{
+			info.FilesystemId,
+			fmt.Sprint(info.Size),
+			string(info.Status.Current),
+			info.Volume,
+			info.Storage,
+			fmt.Sprint(attachments),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return out.Bytes(), nil
+}
+
+// formatFilesystemAttachmentsSummary returns a compact summary of the
+// machines and units a filesystem is attached to, e.g. "3 machines, 2 units".
+func formatFilesystemAttachmentsSummary(attachments *FilesystemAttachments) string {
+	var machines, units int
+	if attachments != nil {
+		machines = len(attachments.Machines)
+		units = len(attachments.Units)
+	}
+	if machines == 0 && units == 0 {
+		return "unattached"
+	}
+	var parts []string
+	if machines > 0 {
+		parts = append(parts, pluralise(machines, "machine"))
+	}
+	if units > 0 {
+		parts = append(parts, pluralise(units, "unit"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pluralise(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
 type filesystemAttachmentInfo struct {
 	FilesystemId string
 	FilesystemInfo
@@ -133,3 +225,23 @@ func (v filesystemAttachmentInfos) Less(i, j int) bool {
 
 	return v[i].FilesystemId < v[j].FilesystemId
 }
+
+// filesystemAttachmentInfosBy orders filesystemAttachmentInfos by
+// one of the keys in filesystemSortKeys.
+type filesystemAttachmentInfosBy struct {
+	filesystemAttachmentInfos
+	key string
+}
+
+func (v filesystemAttachmentInfosBy) Less(i, j int) bool {
+	a, b := v.filesystemAttachmentInfos[i], v.filesystemAttachmentInfos[j]
+	switch v.key {
+	case "size":
+		return a.Size < b.Size
+	case "status":
+		return a.Status.Current < b.Status.Current
+	case "storage":
+		return compareSlashSeparated(a.Storage, b.Storage) < 0
+	}
+	return compareSlashSeparated(a.FilesystemId, b.FilesystemId) < 0
+}
@@ -4,6 +4,8 @@
 package storage
 
 import (
+	"strings"
+
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"launchpad.net/gnuflag"
@@ -30,22 +32,97 @@ options:
 -o, --output (= "")
    specify an output file
 --format (= tabular)
-   specify output format (json|tabular|yaml)
+   specify output format (csv|json|tabular|yaml); csv is only
+   supported with --filesystem
+--utc (= false)
+   display filesystem status times as UTC in ISO format
+--sort (= "")
+   sort tabular and csv filesystem output by id, size, status or storage
+--orphaned (= false)
+   only show filesystems that are not assigned to storage or attached
+--status
+   only show filesystems with these statuses (may be repeated)
+--exit-status (= false)
+   exit with code 2 if any listed filesystem has an error status
+--exit-status-on
+   with --exit-status, the statuses that cause exit code 2 instead of
+   error (may be repeated)
+--writable-only (= false)
+   only show filesystems that are not attached read-only to any machine;
+   read-only attachments are marked "(ro)" in tabular output
+--with-volumes (= false)
+   include the size, provider id and status of the volume backing each
+   filesystem in yaml and json output
+--by-machine (= false)
+   group yaml and json filesystem output by the machine each filesystem
+   is attached to; unattached filesystems are listed under "unattached"
 `
 
 // listCommand returns storage instances.
 type listCommand struct {
 	StorageCommandBase
-	out        cmd.Output
-	ids        []string
-	filesystem bool
-	volume     bool
-	newAPIFunc func() (StorageListAPI, error)
+	out          cmd.Output
+	ids          []string
+	filesystem   bool
+	volume       bool
+	statuses     []string
+	isoTime      bool
+	sortBy       string
+	orphaned     bool
+	byMachine    bool
+	withVolumes  bool
+	writableOnly bool
+	newAPIFunc   func() (StorageListAPI, error)
+
+	exitStatus   bool
+	exitStatuses []string
+
+	// exitStatusMatched records whether a listed filesystem had one of
+	// the --exit-status statuses.
+	exitStatusMatched bool
 }
 
+// exitStatusCode is the exit code used with --exit-status when a
+// listed filesystem has one of the statuses being checked for.
+const exitStatusCode = 2
+
 // Init implements Command.Init.
 func (c *listCommand) Init(args []string) (err error) {
 	c.ids = args
+	if c.exitStatus && !c.filesystem {
+		return errors.New("--exit-status can only be used with --filesystem")
+	}
+	if c.writableOnly && !c.filesystem {
+		return errors.New("--writable-only can only be used with --filesystem")
+	}
+	if c.withVolumes && !c.filesystem {
+		return errors.New("--with-volumes can only be used with --filesystem")
+	}
+	if len(c.statuses) > 0 && !c.filesystem {
+		return errors.New("--status can only be used with --filesystem")
+	}
+	if c.orphaned && !c.filesystem {
+		return errors.New("--orphaned can only be used with --filesystem")
+	}
+	if c.sortBy != "" && !c.filesystem {
+		return errors.New("--sort can only be used with --filesystem")
+	}
+	if c.byMachine && !c.filesystem {
+		return errors.New("--by-machine can only be used with --filesystem")
+	}
+	if c.isoTime && !c.filesystem {
+		return errors.New("--utc can only be used with --filesystem")
+	}
+	if c.out.Name() == "csv" && !c.filesystem {
+		return errors.New("--format csv can only be used with --filesystem")
+	}
+	if len(c.exitStatuses) > 0 && !c.exitStatus {
+		return errors.New("--exit-status-on can only be used with --exit-status")
+	}
+	if c.sortBy != "" && !filesystemSortKeys.Contains(c.sortBy) {
+		return errors.Errorf("invalid sort key %q, expected one of %s",
+			c.sortBy, strings.Join(filesystemSortKeys.SortedValues(), ", "))
+	}
 	return nil
 }
 
@@ -66,10 +143,20 @@ func (c *listCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
 		"yaml":    cmd.FormatYaml,
 		"json":    cmd.FormatJson,
-		"tabular": formatListTabular,
+		"tabular": c.formatListTabular,
+		"csv":     c.formatListCSV,
 	})
 	f.BoolVar(&c.filesystem, "filesystem", false, "list filesystem storage")
 	f.BoolVar(&c.volume, "volume", false, "list volume storage")
+	f.BoolVar(&c.isoTime, "utc", false, "display filesystem status time as UTC in RFC3339 format")
+	f.StringVar(&c.sortBy, "sort", "", "sort tabular and csv filesystem output by id, size, status or storage")
+	f.BoolVar(&c.orphaned, "orphaned", false, "only show filesystems that are not assigned to storage or attached")
+	f.Var(cmd.NewAppendStringsValue(&c.statuses), "status", "only show filesystems with these statuses")
+	f.BoolVar(&c.byMachine, "by-machine", false, "group yaml and json filesystem output by machine")
+	f.BoolVar(&c.writableOnly, "writable-only", false, "only show filesystems that are not attached read-only")
+	f.BoolVar(&c.withVolumes, "with-volumes", false, "include backing volume details in yaml and json filesystem output")
+	f.BoolVar(&c.exitStatus, "exit-status", false, "exit with code 2 if any filesystem has an error status")
+	f.Var(cmd.NewAppendStringsValue(&c.exitStatuses), "exit-status-on", "statuses that cause --exit-status to exit with code 2")
 }
 
 // Run implements Command.Run.
@@ -91,10 +178,15 @@ func (c *listCommand) Run(ctx *cmd.Context) (err error) {
 	if err != nil {
 		return err
 	}
-	if output == nil {
-		return nil
+	if output != nil {
+		if err := c.out.Write(ctx, output); err != nil {
+			return err
+		}
 	}
-	return c.out.Write(ctx, output)
+	if c.exitStatusMatched {
+		return cmd.NewRcPassthroughError(exitStatusCode)
+	}
+	return nil
 }
 
 // StorageAPI defines the API methods that the storage commands use.
@@ -128,7 +220,7 @@ func (c *listCommand) generateListOutput(ctx *cmd.Context, api StorageListAPI) (
 	return output, nil
 }
 
-func formatListTabular(value interface{}) ([]byte, error) {
+func (c *listCommand) formatListTabular(value interface{}) ([]byte, error) {
 
 	switch value.(type) {
 	case map[string]StorageInfo:
@@ -136,7 +228,7 @@ func formatListTabular(value interface{}) ([]byte, error) {
 		return output, err
 
 	case map[string]FilesystemInfo:
-		output, err := formatFilesystemListTabular(value)
+		output, err := formatFilesystemListTabular(value, c.sortBy)
 		return output, err
 
 	case map[string]VolumeInfo:
@@ -147,3 +239,7 @@ func formatListTabular(value interface{}) ([]byte, error) {
 		return nil, errors.Errorf("unexpected value of type %T", value)
 	}
 }
+
+func (c *listCommand) formatListCSV(value interface{}) ([]byte, error) {
+	return formatFilesystemListCSV(value, c.sortBy)
+}
@@ -120,3 +120,57 @@ func createStorageInfo(details params.StorageDetails) (names.StorageTag, Storage
 
 	return storageTag, info, nil
 }
+
+// machineFilesystemAttachments converts the machine attachments of a
+// filesystem, keyed by machine tag, to the form listed by the storage
+// commands, keyed by machine id. It returns nil if there are none.
+func machineFilesystemAttachments(attachments map[string]params.FilesystemAttachmentInfo) (map[string]MachineFilesystemAttachment, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]MachineFilesystemAttachment)
+	for machineTag, attachment := range attachments {
+		machineId, err := attachmentMachineId(machineTag)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result[machineId] = MachineFilesystemAttachment{
+			MountPoint: attachment.MountPoint,
+			ReadOnly:   attachment.ReadOnly,
+		}
+	}
+	return result, nil
+}
+
+// machineVolumeAttachments converts the machine attachments of a volume,
+// keyed by machine tag, to the form listed by the storage commands,
+// keyed by machine id. It returns nil if there are none.
+func machineVolumeAttachments(attachments map[string]params.VolumeAttachmentInfo) (map[string]MachineVolumeAttachment, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]MachineVolumeAttachment)
+	for machineTag, attachment := range attachments {
+		machineId, err := attachmentMachineId(machineTag)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result[machineId] = MachineVolumeAttachment{
+			DeviceName: attachment.DeviceName,
+			DeviceLink: attachment.DeviceLink,
+			BusAddress: attachment.BusAddress,
+			ReadOnly:   attachment.ReadOnly,
+		}
+	}
+	return result, nil
+}
+
+// attachmentMachineId returns the id of the machine with the given tag,
+// which keys a machine attachment.
+func attachmentMachineId(machineTag string) (string, error) {
+	machineId, err := idFromTag(machineTag)
+	if err != nil {
+		return "", errors.Annotate(err, "machine attachment")
+	}
+	return machineId, nil
+}
@@ -131,20 +131,11 @@ func createVolumeInfo(details params.VolumeDetails) (names.VolumeTag, VolumeInfo
 		common.FormatTime(details.Status.Since, false),
 	}
 
-	if len(details.MachineAttachments) > 0 {
-		machineAttachments := make(map[string]MachineVolumeAttachment)
-		for machineTag, attachment := range details.MachineAttachments {
-			machineId, err := idFromTag(machineTag)
-			if err != nil {
-				return names.VolumeTag{}, VolumeInfo{}, errors.Trace(err)
-			}
-			machineAttachments[machineId] = MachineVolumeAttachment{
-				attachment.DeviceName,
-				attachment.DeviceLink,
-				attachment.BusAddress,
-				attachment.ReadOnly,
-			}
-		}
+	machineAttachments, err := machineVolumeAttachments(details.MachineAttachments)
+	if err != nil {
+		return names.VolumeTag{}, VolumeInfo{}, errors.Trace(err)
+	}
+	if machineAttachments != nil {
 		info.Attachments = &VolumeAttachments{
 			Machines: machineAttachments,
 		}
@@ -146,9 +146,9 @@ func (s *DeployLocalSuite) assertBindings(c *gc.C, service *state.Service, expec
 
 func (s *DeployLocalSuite) TestDeployWithSomeSpecifiedBindings(c *gc.C) {
 	wordpressCharm := s.addWordpressCharm(c)
-	_, err := s.State.AddSpace("db", "", nil, false)
+	_, err := s.State.AddSpace("db", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("public", "", nil, false)
+	_, err = s.State.AddSpace("public", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	service, err := juju.DeployService(s.State,
@@ -178,11 +178,11 @@ func (s *DeployLocalSuite) TestDeployWithSomeSpecifiedBindings(c *gc.C) {
 
 func (s *DeployLocalSuite) TestDeployWithBoundRelationNamesAndExtraBindingsNames(c *gc.C) {
 	wordpressCharm := s.addWordpressCharmWithExtraBindings(c)
-	_, err := s.State.AddSpace("db", "", nil, false)
+	_, err := s.State.AddSpace("db", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("public", "", nil, false)
+	_, err = s.State.AddSpace("public", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("internal", "", nil, false)
+	_, err = s.State.AddSpace("internal", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	service, err := juju.DeployService(s.State,
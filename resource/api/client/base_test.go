@@ -102,6 +102,7 @@ type stubFacade struct {
 
 	apiResults map[string]api.ResourcesResult
 	pendingIDs []string
+	revisions  []int
 }
 
 func newStubFacade(c *gc.C, stub *testing.Stub) *stubFacade {
@@ -134,6 +135,8 @@ func newStubFacade(c *gc.C, stub *testing.Stub) *stubFacade {
 			}
 		case *api.AddPendingResourcesResult:
 			typedResponse.PendingIDs = s.pendingIDs
+			typedResponse.Revisions = s.revisions
+		case *params.ErrorResult:
 		default:
 			c.Errorf("bad type %T", response)
 		}
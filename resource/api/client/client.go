@@ -9,15 +9,19 @@ import (
 	"strings"
 
 	"github.com/juju/errors"
+	"github.com/juju/loggo"
 	charmresource "gopkg.in/juju/charm.v6-unstable/resource"
 	"gopkg.in/macaroon.v1"
 
 	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/charmstore"
 	"github.com/juju/juju/resource"
 	"github.com/juju/juju/resource/api"
 )
 
+var logger = loggo.GetLogger("juju.resource.api.client")
+
 // TODO(ericsnow) Move FacadeCaller to a component-central package.
 
 // FacadeCaller has the api/base.FacadeCaller methods needed for the component.
@@ -122,31 +126,62 @@ type AddPendingResourcesArgs struct {
 // AddPendingResources sends the provided resource info up to Juju
 // without making it available yet.
 func (c Client) AddPendingResources(args AddPendingResourcesArgs) (pendingIDs []string, err error) {
+	pendingIDs, _, err = c.AddPendingResourcesWithRevisions(args)
+	return pendingIDs, err
+}
+
+// AddPendingResourcesWithRevisions does the same as AddPendingResources,
+// but also returns the revision the controller resolved each resource
+// to. The revisions are nil if the controller does not report them.
+func (c Client) AddPendingResourcesWithRevisions(args AddPendingResourcesArgs) (pendingIDs []string, revisions []int, err error) {
 	apiArgs, err := api.NewAddPendingResourcesArgs(args.ServiceID, args.CharmID, args.CharmStoreMacaroon, args.Resources)
 	if err != nil {
-		return nil, errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 
 	var result api.AddPendingResourcesResult
 	if err := c.FacadeCall("AddPendingResources", &apiArgs, &result); err != nil {
-		return nil, errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 	if result.Error != nil {
 		err := common.RestoreError(result.Error)
-		return nil, errors.Trace(err)
+		return nil, nil, errors.Trace(err)
 	}
 
 	if len(result.PendingIDs) != len(args.Resources) {
-		return nil, errors.Errorf("bad data from server: expected %d IDs, got %d", len(args.Resources), len(result.PendingIDs))
+		return nil, nil, errors.Errorf("bad data from server: expected %d IDs, got %d", len(args.Resources), len(result.PendingIDs))
 	}
 	for i, id := range result.PendingIDs {
 		if id == "" {
-			return nil, errors.Errorf("bad data from server: got an empty ID for resource %q", args.Resources[i].Name)
+			return nil, nil, errors.Errorf("bad data from server: got an empty ID for resource %q", args.Resources[i].Name)
 		}
 		// TODO(ericsnow) Do other validation?
 	}
+	if result.Revisions != nil && len(result.Revisions) != len(args.Resources) {
+		return nil, nil, errors.Errorf("bad data from server: expected %d revisions, got %d", len(args.Resources), len(result.Revisions))
+	}
+
+	return result.PendingIDs, result.Revisions, nil
+}
+
+// RemovePendingResources removes the identified pending resources,
+// given as a map of resource name to pending ID, from Juju. It is not
+// an error if any of them no longer exist.
+func (c Client) RemovePendingResources(serviceID string, pendingIDs map[string]string) error {
+	args, err := api.NewRemovePendingResourcesArgs(serviceID, pendingIDs)
+	if err != nil {
+		return errors.Trace(err)
+	}
 
-	return result.PendingIDs, nil
+	var result params.ErrorResult
+	if err := c.FacadeCall("RemovePendingResources", &args, &result); err != nil {
+		return errors.Trace(err)
+	}
+	if result.Error != nil {
+		err := common.RestoreError(result.Error)
+		return errors.Trace(err)
+	}
+	return nil
 }
 
 // AddPendingResource sends the provided resource blob up to Juju
@@ -175,6 +210,10 @@ func (c Client) AddPendingResource(serviceID string, res charmresource.Resource,
 
 		var response api.UploadResult // ignored
 		if err := c.doer.Do(req, reader, &response); err != nil {
+			// Don't leave behind the pending resource added above.
+			if err := c.RemovePendingResources(serviceID, map[string]string{res.Name: pendingID}); err != nil {
+				logger.Errorf("cannot remove pending resource %q (%s): %v", res.Name, pendingID, err)
+			}
 			return "", errors.Trace(err)
 		}
 	}
@@ -17,7 +17,9 @@ import (
 	"gopkg.in/juju/charm.v6-unstable"
 	charmresource "gopkg.in/juju/charm.v6-unstable/resource"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/charmstore"
+	"github.com/juju/juju/resource/api"
 	"github.com/juju/juju/resource/api/client"
 )
 
@@ -111,6 +113,73 @@ func (s *UploadSuite) TestPendingResources(c *gc.C) {
 	c.Check(pendingIDs, jc.DeepEquals, expected)
 }
 
+func (s *UploadSuite) TestPendingResourcesWithRevisions(c *gc.C) {
+	res, apiResult := newResourceResult(c, "a-service", "spam")
+	resources := []charmresource.Resource{res[0].Resource}
+	s.response.Resource = apiResult.Resources[0]
+	s.facade.pendingIDs = []string{"some-unique-ID"}
+	s.facade.revisions = []int{7}
+	cl := client.NewClient(s.facade, s, s.facade)
+
+	pendingIDs, revisions, err := cl.AddPendingResourcesWithRevisions(client.AddPendingResourcesArgs{
+		ServiceID: "a-service",
+		CharmID: charmstore.CharmID{
+			URL: charm.MustParseURL("cs:~a-user/trusty/spam-5"),
+		},
+		Resources: resources,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "FacadeCall")
+	c.Check(pendingIDs, jc.DeepEquals, []string{"some-unique-ID"})
+	c.Check(revisions, jc.DeepEquals, []int{7})
+}
+
+func (s *UploadSuite) TestPendingResourcesWithoutRevisions(c *gc.C) {
+	res, apiResult := newResourceResult(c, "a-service", "spam")
+	resources := []charmresource.Resource{res[0].Resource}
+	s.response.Resource = apiResult.Resources[0]
+	s.facade.pendingIDs = []string{"some-unique-ID"}
+	cl := client.NewClient(s.facade, s, s.facade)
+
+	pendingIDs, revisions, err := cl.AddPendingResourcesWithRevisions(client.AddPendingResourcesArgs{
+		ServiceID: "a-service",
+		Resources: resources,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(pendingIDs, jc.DeepEquals, []string{"some-unique-ID"})
+	c.Check(revisions, gc.IsNil)
+}
+
+func (s *UploadSuite) TestRemovePendingResources(c *gc.C) {
+	cl := client.NewClient(s.facade, s, s.facade)
+
+	err := cl.RemovePendingResources("a-service", map[string]string{
+		"spam": "some-unique-ID",
+		"eggs": "other-unique-ID",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "FacadeCall")
+	s.stub.CheckCall(c, 0, "FacadeCall",
+		"RemovePendingResources",
+		&api.RemovePendingResourcesArgs{
+			Entity: params.Entity{
+				Tag: "service-a-service",
+			},
+			Resources: []api.PendingResource{{
+				Name:      "eggs",
+				PendingID: "other-unique-ID",
+			}, {
+				Name:      "spam",
+				PendingID: "some-unique-ID",
+			}},
+		},
+		&params.ErrorResult{},
+	)
+}
+
 func (s *UploadSuite) TestPendingResourceOkay(c *gc.C) {
 	res, apiResult := newResourceResult(c, "a-service", "spam")
 	uuid, err := utils.NewUUID()
@@ -211,6 +280,20 @@ func (s *UploadSuite) TestPendingResourceRequestFailed(c *gc.C) {
 		"Read",
 		"Seek",
 		"Do",
+		"FacadeCall",
+	)
+	s.stub.CheckCall(c, 5, "FacadeCall",
+		"RemovePendingResources",
+		&api.RemovePendingResourcesArgs{
+			Entity: params.Entity{
+				Tag: "service-a-service",
+			},
+			Resources: []api.PendingResource{{
+				Name:      "spam",
+				PendingID: "some-unique-id",
+			}},
+		},
+		&params.ErrorResult{},
 	)
 }
 
@@ -6,6 +6,7 @@ package api
 // TODO(ericsnow) Eliminate the dependence on apiserver if possible.
 
 import (
+	"sort"
 	"strings"
 	"time"
 
@@ -87,6 +88,57 @@ type AddPendingResourcesResult struct {
 	// PendingIDs holds the "pending ID" for each of the requested
 	// resources.
 	PendingIDs []string
+
+	// Revisions holds the revision each of the requested resources
+	// was resolved to, in the same order as PendingIDs. It is not
+	// set by older controllers.
+	Revisions []int
+}
+
+// RemovePendingResourcesArgs holds the arguments to the
+// RemovePendingResources API endpoint.
+type RemovePendingResourcesArgs struct {
+	params.Entity
+
+	// Resources identifies the pending resources to remove.
+	Resources []PendingResource
+}
+
+// PendingResource identifies a pending resource of a service.
+type PendingResource struct {
+	// Name is the name of the resource.
+	Name string
+
+	// PendingID is the "pending ID" of the resource.
+	PendingID string
+}
+
+// NewRemovePendingResourcesArgs returns the arguments for the
+// RemovePendingResources API endpoint. The pending resources to remove
+// are given as a map of resource name to pending ID.
+func NewRemovePendingResourcesArgs(serviceID string, pendingIDs map[string]string) (RemovePendingResourcesArgs, error) {
+	var args RemovePendingResourcesArgs
+
+	if !names.IsValidService(serviceID) {
+		return args, errors.Errorf("invalid service %q", serviceID)
+	}
+	args.Tag = names.NewServiceTag(serviceID).String()
+
+	resourceNames := make([]string, 0, len(pendingIDs))
+	for name := range pendingIDs {
+		resourceNames = append(resourceNames, name)
+	}
+	sort.Strings(resourceNames)
+	for _, name := range resourceNames {
+		if pendingIDs[name] == "" {
+			return args, errors.Errorf("missing pending ID for resource %q", name)
+		}
+		args.Resources = append(args.Resources, PendingResource{
+			Name:      name,
+			PendingID: pendingIDs[name],
+		})
+	}
+	return args, nil
 }
 
 // ResourcesResults holds the resources that result
@@ -136,6 +136,15 @@ func (s *stubDataStore) UpdatePendingResource(serviceID, pendingID, userID strin
 	return s.ReturnUpdatePendingResource, nil
 }
 
+func (s *stubDataStore) RemovePendingResource(serviceID, name, pendingID string) error {
+	s.stub.AddCall("RemovePendingResource", serviceID, name, pendingID)
+	if err := s.stub.NextErr(); err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
 type stubCSClient struct {
 	*testing.Stub
 
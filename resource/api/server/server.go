@@ -85,6 +85,10 @@ type resourceInfoStore interface {
 	// it is resolved. The returned ID is used to identify the pending
 	// resources when resolving it.
 	AddPendingResource(serviceID, userID string, chRes charmresource.Resource, r io.Reader) (string, error)
+
+	// RemovePendingResource removes the identified pending resource
+	// and its data. It is not an error if it does not exist.
+	RemovePendingResource(serviceID, name, pendingID string) error
 }
 
 // ListResources returns the list of resources for the given service.
@@ -115,6 +119,28 @@ func (f Facade) ListResources(args api.ListResourcesArgs) (api.ResourcesResults,
 	return r, nil
 }
 
+// RemovePendingResources removes the identified pending resources from
+// the Juju model, such as those added by a deploy that then failed. It
+// is not an error if any of them no longer exist.
+func (f Facade) RemovePendingResources(args api.RemovePendingResourcesArgs) (params.ErrorResult, error) {
+	var result params.ErrorResult
+
+	tag, apiErr := parseServiceTag(args.Tag)
+	if apiErr != nil {
+		result.Error = apiErr
+		return result, nil
+	}
+	serviceID := tag.Id()
+
+	for _, res := range args.Resources {
+		if err := f.store.RemovePendingResource(serviceID, res.Name, res.PendingID); err != nil {
+			result.Error = common.ServerError(err)
+			return result, nil
+		}
+	}
+	return result, nil
+}
+
 // AddPendingResources adds the provided resources (info) to the Juju
 // model in a pending state, meaning they are not available until
 // resolved.
@@ -129,21 +155,22 @@ func (f Facade) AddPendingResources(args api.AddPendingResourcesArgs) (api.AddPe
 	serviceID := tag.Id()
 
 	channel := csparams.Channel(args.Channel)
-	ids, err := f.addPendingResources(serviceID, args.URL, channel, args.CharmStoreMacaroon, args.Resources)
+	ids, revisions, err := f.addPendingResources(serviceID, args.URL, channel, args.CharmStoreMacaroon, args.Resources)
 	if err != nil {
 		result.Error = common.ServerError(err)
 		return result, nil
 	}
 	result.PendingIDs = ids
+	result.Revisions = revisions
 	return result, nil
 }
 
-func (f Facade) addPendingResources(serviceID, chRef string, channel csparams.Channel, csMac *macaroon.Macaroon, apiResources []api.CharmResource) ([]string, error) {
+func (f Facade) addPendingResources(serviceID, chRef string, channel csparams.Channel, csMac *macaroon.Macaroon, apiResources []api.CharmResource) ([]string, []int, error) {
 	var resources []charmresource.Resource
 	for _, apiRes := range apiResources {
 		res, err := api.API2CharmResource(apiRes)
 		if err != nil {
-			return nil, errors.Annotatef(err, "bad resource info for %q", apiRes.Name)
+			return nil, nil, errors.Annotatef(err, "bad resource info for %q", apiRes.Name)
 		}
 		resources = append(resources, res)
 	}
@@ -151,7 +178,7 @@ func (f Facade) addPendingResources(serviceID, chRef string, channel csparams.Ch
 	if chRef != "" {
 		cURL, err := charm.ParseURL(chRef)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		switch cURL.Schema {
@@ -162,30 +189,32 @@ func (f Facade) addPendingResources(serviceID, chRef string, channel csparams.Ch
 			}
 			resources, err = f.resolveCharmstoreResources(id, csMac, resources)
 			if err != nil {
-				return nil, errors.Trace(err)
+				return nil, nil, errors.Trace(err)
 			}
 		case "local":
 			resources, err = f.resolveLocalResources(resources)
 			if err != nil {
-				return nil, errors.Trace(err)
+				return nil, nil, errors.Trace(err)
 			}
 		default:
-			return nil, errors.Errorf("unrecognized charm schema %q", cURL.Schema)
+			return nil, nil, errors.Errorf("unrecognized charm schema %q", cURL.Schema)
 		}
 	}
 
 	var ids []string
+	var revisions []int
 	for _, res := range resources {
 		pendingID, err := f.addPendingResource(serviceID, res)
 		if err != nil {
 			// We don't bother aggregating errors since a partial
 			// completion is disruptive and a retry of this endpoint
 			// is not expensive.
-			return nil, err
+			return nil, nil, err
 		}
 		ids = append(ids, pendingID)
+		revisions = append(revisions, res.Revision)
 	}
-	return ids, nil
+	return ids, revisions, nil
 }
 
 func (f Facade) resolveCharmstoreResources(id charmstore.CharmID, csMac *macaroon.Macaroon, resources []charmresource.Resource) ([]charmresource.Resource, error) {
@@ -43,6 +43,9 @@ func (s *AddPendingResourcesSuite) TestNoURL(c *gc.C) {
 		PendingIDs: []string{
 			id1,
 		},
+		Revisions: []int{
+			res1.Revision,
+		},
 	})
 }
 
@@ -80,6 +83,9 @@ func (s *AddPendingResourcesSuite) TestWithURLUpToDate(c *gc.C) {
 		PendingIDs: []string{
 			id1,
 		},
+		Revisions: []int{
+			res1.Revision,
+		},
 	})
 }
 
@@ -119,6 +125,9 @@ func (s *AddPendingResourcesSuite) TestWithURLMismatchComplete(c *gc.C) {
 		PendingIDs: []string{
 			id1,
 		},
+		Revisions: []int{
+			res1.Revision,
+		},
 	})
 }
 
@@ -167,6 +176,9 @@ func (s *AddPendingResourcesSuite) TestWithURLMismatchIncomplete(c *gc.C) {
 		PendingIDs: []string{
 			id1,
 		},
+		Revisions: []int{
+			expected.Revision,
+		},
 	})
 }
 
@@ -210,6 +222,9 @@ func (s *AddPendingResourcesSuite) TestWithURLNoRevision(c *gc.C) {
 		PendingIDs: []string{
 			id1,
 		},
+		Revisions: []int{
+			res1.Revision,
+		},
 	})
 }
 
@@ -246,6 +261,9 @@ func (s *AddPendingResourcesSuite) TestLocalCharm(c *gc.C) {
 		PendingIDs: []string{
 			id1,
 		},
+		Revisions: []int{
+			expected.Revision,
+		},
 	})
 }
 
@@ -286,6 +304,9 @@ func (s *AddPendingResourcesSuite) TestWithURLUpload(c *gc.C) {
 		PendingIDs: []string{
 			id1,
 		},
+		Revisions: []int{
+			res1.Revision,
+		},
 	})
 }
 
@@ -351,6 +372,9 @@ func (s *AddPendingResourcesSuite) TestUnknownResource(c *gc.C) {
 		PendingIDs: []string{
 			id1,
 		},
+		Revisions: []int{
+			res1.Revision,
+		},
 	})
 }
 
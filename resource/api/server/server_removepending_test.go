@@ -0,0 +1,65 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package server_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/resource/api"
+	"github.com/juju/juju/resource/api/server"
+)
+
+var _ = gc.Suite(&RemovePendingResourcesSuite{})
+
+type RemovePendingResourcesSuite struct {
+	BaseSuite
+}
+
+func (s *RemovePendingResourcesSuite) TestOkay(c *gc.C) {
+	facade, err := server.NewFacade(s.data, s.newCSClient)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := facade.RemovePendingResources(api.RemovePendingResourcesArgs{
+		Entity: params.Entity{
+			Tag: "service-a-service",
+		},
+		Resources: []api.PendingResource{{
+			Name:      "eggs",
+			PendingID: "some-unique-ID",
+		}, {
+			Name:      "spam",
+			PendingID: "other-unique-ID",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "RemovePendingResource", "RemovePendingResource")
+	s.stub.CheckCall(c, 0, "RemovePendingResource", "a-service", "eggs", "some-unique-ID")
+	s.stub.CheckCall(c, 1, "RemovePendingResource", "a-service", "spam", "other-unique-ID")
+	c.Check(result, jc.DeepEquals, params.ErrorResult{})
+}
+
+func (s *RemovePendingResourcesSuite) TestError(c *gc.C) {
+	failure := errors.New("<failure>")
+	s.stub.SetErrors(failure)
+	facade, err := server.NewFacade(s.data, s.newCSClient)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := facade.RemovePendingResources(api.RemovePendingResourcesArgs{
+		Entity: params.Entity{
+			Tag: "service-a-service",
+		},
+		Resources: []api.PendingResource{{
+			Name:      "spam",
+			PendingID: "some-unique-ID",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "RemovePendingResource")
+	c.Check(result.Error, gc.ErrorMatches, "<failure>")
+}
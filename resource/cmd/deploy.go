@@ -4,25 +4,44 @@
 package cmd
 
 import (
+	"compress/gzip"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"github.com/juju/utils/tar"
+	"golang.org/x/net/context"
 	charmresource "gopkg.in/juju/charm.v6-unstable/resource"
+	csparams "gopkg.in/juju/charmrepo.v2-unstable/csclient/params"
+	"gopkg.in/macaroon-bakery.v1/bakery/checkers"
 	"gopkg.in/macaroon.v1"
 
 	"github.com/juju/juju/charmstore"
 )
 
+var logger = loggo.GetLogger("juju.resource.cmd")
+
 // DeployClient exposes the functionality of the resources API needed
 // for deploy.
 type DeployClient interface {
 	// AddPendingResources adds pending metadata for store-based resources.
-	AddPendingResources(serviceID string, chID charmstore.CharmID, csMac *macaroon.Macaroon, resources []charmresource.Resource) (ids []string, err error)
+	// It also returns the revision the controller resolved each resource
+	// to, or nil revisions if the controller does not report them.
+	AddPendingResources(serviceID string, chID charmstore.CharmID, csMac *macaroon.Macaroon, resources []charmresource.Resource) (ids []string, revisions []int, err error)
 
 	// AddPendingResource uploads data and metadata for a pending resource for the given service.
 	AddPendingResource(serviceID string, resource charmresource.Resource, filename string, r io.ReadSeeker) (id string, err error)
+
+	// RemovePendingResources removes the pending resources, given as a
+	// map of resource name to pending ID, for the given service.
+	RemovePendingResources(serviceID string, pendingIDs map[string]string) error
 }
 
 // DeployResourcesArgs holds the arguments to DeployResources().
@@ -34,57 +53,232 @@ type DeployResourcesArgs struct {
 	CharmID charmstore.CharmID
 
 	// CharmStoreMacaroon is the macaroon to use for the charm when
-	// interacting with the charm store.
+	// interacting with the charm store. Nothing is uploaded if it
+	// has already expired.
 	CharmStoreMacaroon *macaroon.Macaroon
 
 	// Filenames is the set of resources for which a filename
-	// was provided at the command-line.
+	// was provided at the command-line. A filename that refers to
+	// a directory is uploaded as a gzipped tarball of its contents.
 	Filenames map[string]string
 
 	// Revisions is the set of resources for which a revision
 	// was provided at the command-line.
 	Revisions map[string]int
 
+	// Channels holds the charm store channel to resolve each store
+	// resource from, for those resources that should not be resolved
+	// from the charm's own channel.
+	Channels map[string]csparams.Channel
+
 	// ResourcesMeta holds the charm metadata for each of the resources
 	// that should be added/updated on the controller.
 	ResourcesMeta map[string]charmresource.Meta
 
 	// Client is the resources API client to use during deploy.
 	Client DeployClient
+
+	// Progress, if set, is written a line as each file is uploaded
+	// and a summary of where each resource came from once all the
+	// resources have been added.
+	Progress io.Writer
+}
+
+// DeployResult describes a pending resource added by DeployResources.
+type DeployResult struct {
+	// Name is the name of the resource.
+	Name string
+
+	// PendingID is the ID of the pending resource.
+	PendingID string
+
+	// Origin is where the resource's content comes from: a file
+	// uploaded from the client, or the charm store.
+	Origin charmresource.Origin
+
+	// Revision is the charm store revision the controller resolved the
+	// resource to. If the controller does not report it, this is the
+	// revision that was requested, or -1 if none was. It is always -1
+	// for uploaded resources.
+	Revision int
+
+	// Size is the number of bytes uploaded, for uploaded resources.
+	// The size of store resources is determined by the controller,
+	// so it is 0 here.
+	Size int64
 }
 
 // DeployResources uploads the bytes for the given files to the server and
 // creates pending resource metadata for the all resource mentioned in the
 // metadata. It returns a map of resource name to pending resource IDs.
 func DeployResources(args DeployResourcesArgs) (ids map[string]string, err error) {
+	return DeployResourcesWithContext(context.Background(), args)
+}
+
+// DeployResourcesWithContext does the same as DeployResources, but stops
+// when ctx is cancelled, aborting any upload that is in progress. When
+// deploying the resources fails or is cancelled, any pending resources
+// that were already added are removed again.
+func DeployResourcesWithContext(ctx context.Context, args DeployResourcesArgs) (ids map[string]string, err error) {
+	results, err := deployResources(ctx, args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return pendingIDs(results), nil
+}
+
+// DeployResourcesWithResults does the same as DeployResources, but
+// returns a DeployResult for each resource, keyed by resource name.
+func DeployResourcesWithResults(args DeployResourcesArgs) (map[string]DeployResult, error) {
+	return deployResources(context.Background(), args)
+}
+
+func deployResources(ctx context.Context, args DeployResourcesArgs) (map[string]DeployResult, error) {
 	d := deployUploader{
 		serviceID: args.ServiceID,
 		chID:      args.CharmID,
 		csMac:     args.CharmStoreMacaroon,
 		client:    args.Client,
 		resources: args.ResourcesMeta,
+		channels:  args.Channels,
 		osOpen:    func(s string) (ReadSeekCloser, error) { return os.Open(s) },
 		osStat:    func(s string) error { _, err := os.Stat(s); return err },
+		progress:  args.Progress,
+		ctx:       ctx,
 	}
 
-	ids, err = d.upload(args.Filenames, args.Revisions)
+	results, err := d.upload(args.Filenames, args.Revisions)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	return ids, nil
+	return results, nil
+}
+
+// pendingIDs reduces results to a map of resource name to pending ID.
+func pendingIDs(results map[string]DeployResult) map[string]string {
+	ids := make(map[string]string)
+	for name, result := range results {
+		ids[name] = result.PendingID
+	}
+	return ids
+}
+
+// archiveResourceDirs returns a copy of filenames in which each path
+// that refers to a directory is replaced by the path of a tarball of
+// that directory, named with the extension of the resource's path in
+// resources. Paths that are not directories, including ones that do
+// not exist, are left for the uploader to deal with. The returned
+// cleanup function removes any tarballs that were created.
+func archiveResourceDirs(filenames map[string]string, resources map[string]charmresource.Meta) (_ map[string]string, cleanup func(), err error) {
+	cleanup = func() {}
+	result := make(map[string]string)
+	var tempDir string
+	defer func() {
+		if err != nil && tempDir != "" {
+			os.RemoveAll(tempDir)
+		}
+	}()
+	for name, path := range filenames {
+		result[name] = path
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+		ext, ok := dirArchiveExtension(resources[name].Path)
+		if !ok {
+			return nil, nil, errors.Errorf(
+				"cannot upload directory %q for resource %q: expected a %q file, only .tar, .tar.gz and .tgz resources can be uploaded from a directory",
+				path, name, resources[name].Path,
+			)
+		}
+		if tempDir == "" {
+			tempDir, err = ioutil.TempDir("", "juju-resources-")
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+		}
+		archive, err := archiveDir(path, tempDir, ext)
+		if err != nil {
+			return nil, nil, errors.Annotatef(err, "archiving directory for resource %q", name)
+		}
+		result[name] = archive
+	}
+	if tempDir != "" {
+		cleanup = func() { os.RemoveAll(tempDir) }
+	}
+	return result, cleanup, nil
 }
 
+// dirArchiveExtension returns the extension to give the tarball of a
+// directory uploaded for a resource with the given path. The controller
+// rejects uploads whose extension differs from the resource's path, so
+// only resources that are tarballs can be uploaded from a directory.
+func dirArchiveExtension(resourcePath string) (string, bool) {
+	for _, ext := range []string{".tar.gz", ".tgz", ".tar"} {
+		if strings.HasSuffix(resourcePath, ext) {
+			return ext, true
+		}
+	}
+	return "", false
+}
+
+// archiveDir writes a tarball of dir into targetDir and returns its
+// path, which ends in ext. The tarball is gzipped unless ext is ".tar".
+// Entries in the tarball are rooted at the base name of dir.
+func archiveDir(dir, targetDir, ext string) (_ string, err error) {
+	dir = filepath.Clean(dir)
+	f, err := ioutil.TempFile(targetDir, filepath.Base(dir)+"-")
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	defer f.Close()
+
+	var w io.WriteCloser = nopWriteCloser{f}
+	if ext != ".tar" {
+		w = gzip.NewWriter(f)
+	}
+	stripPrefix := filepath.Dir(dir) + string(os.PathSeparator)
+	if _, err := tar.TarFiles([]string{dir}, w, stripPrefix); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := w.Close(); err != nil {
+		return "", errors.Trace(err)
+	}
+	if err := f.Close(); err != nil {
+		return "", errors.Trace(err)
+	}
+
+	// The controller checks the extension of uploaded resources.
+	archive := f.Name() + ext
+	if err := os.Rename(f.Name(), archive); err != nil {
+		return "", errors.Trace(err)
+	}
+	return archive, nil
+}
+
+// nopWriteCloser adds a Close method that does nothing to a Writer.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
 type deployUploader struct {
 	serviceID string
 	chID      charmstore.CharmID
 	csMac     *macaroon.Macaroon
 	resources map[string]charmresource.Meta
+	channels  map[string]csparams.Channel
 	client    DeployClient
 	osOpen    func(path string) (ReadSeekCloser, error)
 	osStat    func(path string) error
+	progress  io.Writer
+
+	// ctx, if set, stops the upload when it is cancelled.
+	ctx context.Context
 }
 
-func (d deployUploader) upload(files map[string]string, revisions map[string]int) (map[string]string, error) {
+func (d deployUploader) upload(files map[string]string, revisions map[string]int) (_ map[string]DeployResult, err error) {
 	if err := d.validateResources(); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -97,43 +291,177 @@ func (d deployUploader) upload(files map[string]string, revisions map[string]int
 		return nil, errors.Trace(err)
 	}
 
+	if err := checkMacaroon(d.csMac, time.Now()); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// Directories are only archived once the arguments are known to be
+	// valid, so that invalid ones don't leave tarballs to clean up.
+	files, cleanup, err := archiveResourceDirs(files, d.resources)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer cleanup()
+
 	storeResources := d.storeResources(files, revisions)
-	pending := map[string]string{}
-	if len(storeResources) > 0 {
-		ids, err := d.client.AddPendingResources(d.serviceID, d.chID, d.csMac, storeResources)
+	pending := map[string]DeployResult{}
+	defer func() {
+		if err != nil {
+			d.removePending(pending)
+		}
+	}()
+	byChannel := d.storeResourcesByChannel(storeResources)
+	for _, channel := range sortedChannels(byChannel) {
+		if err := d.cancelled(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		chID := d.chID
+		chID.Channel = channel
+		resources := byChannel[channel]
+		ids, revisions, err := d.client.AddPendingResources(d.serviceID, chID, d.csMac, resources)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
 		// guaranteed 1:1 correlation between ids and resources.
-		for i, res := range storeResources {
-			pending[res.Name] = ids[i]
+		for i, res := range resources {
+			revision := res.Revision
+			if len(revisions) == len(resources) {
+				revision = revisions[i]
+			}
+			pending[res.Name] = DeployResult{
+				Name:      res.Name,
+				PendingID: ids[i],
+				Origin:    res.Origin,
+				Revision:  revision,
+			}
 		}
 	}
 
-	for name, filename := range files {
-		id, err := d.uploadFile(name, filename)
+	for _, name := range sortedNames(files) {
+		if err := d.cancelled(); err != nil {
+			return nil, errors.Trace(err)
+		}
+		filename := files[name]
+		d.progressf("uploading resource %q from %s", name, filename)
+		result, err := d.uploadFile(name, filename)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		pending[name] = id
+		pending[name] = result
 	}
 
+	d.writeSummary(files, storeResources)
 	return pending, nil
 }
 
-func (d deployUploader) checkFiles(files map[string]string) error {
-	for name, path := range files {
-		err := d.osStat(path)
-		if os.IsNotExist(err) {
-			return errors.Annotatef(err, "file for resource %q", name)
+// removePending removes the pending resources added by a deploy that
+// then failed, so that they are not left behind. A failure to remove
+// them is logged, so that the error which stopped the deploy is the one
+// reported.
+func (d deployUploader) removePending(pending map[string]DeployResult) {
+	if len(pending) == 0 {
+		return
+	}
+	if err := d.client.RemovePendingResources(d.serviceID, pendingIDs(pending)); err != nil {
+		logger.Errorf("cannot remove pending resources for service %q: %v", d.serviceID, err)
+	}
+}
+
+// cancelled returns the error of the uploader's context, if it has one
+// and it has been cancelled.
+func (d deployUploader) cancelled() error {
+	if d.ctx == nil {
+		return nil
+	}
+	if err := d.ctx.Err(); err != nil {
+		return errors.Annotate(err, "deploying resources")
+	}
+	return nil
+}
+
+// checkMacaroon verifies that the charm store macaroon, if there is
+// one, has not expired by the given time, so that a deploy doesn't
+// strand pending resources by failing part way through. The macaroon's
+// signature can only be checked by the charm store itself.
+func checkMacaroon(m *macaroon.Macaroon, now time.Time) error {
+	if m == nil {
+		return nil
+	}
+	for _, cav := range m.Caveats() {
+		if cav.Location != "" {
+			// Third party caveats are discharged elsewhere.
+			continue
+		}
+		cond, arg, err := checkers.ParseCaveat(cav.Id)
+		if err != nil || cond != checkers.CondTimeBefore {
+			continue
 		}
+		expiry, err := time.Parse(time.RFC3339Nano, arg)
 		if err != nil {
-			return errors.Annotatef(err, "can't read file for resource %q", name)
+			return errors.Errorf("charm store authentication failed: invalid expiry time %q", arg)
+		}
+		if !now.Before(expiry) {
+			return errors.Errorf("charm store authentication failed: macaroon expired at %s", expiry.Format(time.RFC3339))
 		}
 	}
 	return nil
 }
 
+// progressf writes a line to the progress writer, if there is one.
+func (d deployUploader) progressf(format string, args ...interface{}) {
+	if d.progress != nil {
+		fmt.Fprintf(d.progress, format+"\n", args...)
+	}
+}
+
+// writeSummary writes to the progress writer which resources were
+// uploaded from files and which were resolved from the charm store.
+func (d deployUploader) writeSummary(files map[string]string, storeResources []charmresource.Resource) {
+	if len(files) > 0 {
+		d.progressf("resources uploaded from files: %s", strings.Join(sortedNames(files), ", "))
+	}
+	if len(storeResources) > 0 {
+		fromStore := make([]string, len(storeResources))
+		for i, res := range storeResources {
+			fromStore[i] = res.Name
+			if res.Revision >= 0 {
+				fromStore[i] += fmt.Sprintf(" (revision %d)", res.Revision)
+			}
+		}
+		sort.Strings(fromStore)
+		d.progressf("resources from the charm store: %s", strings.Join(fromStore, ", "))
+	}
+}
+
+// checkFiles verifies that the file for each resource exists and can
+// be read, so that problems are reported before anything is uploaded.
+// Every bad file is reported, not just the first.
+func (d deployUploader) checkFiles(files map[string]string) error {
+	var errs []error
+	for _, name := range sortedNames(files) {
+		if err := d.checkFile(name, files[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return combineErrors(errs)
+}
+
+func (d deployUploader) checkFile(name, path string) error {
+	err := d.osStat(path)
+	if os.IsNotExist(err) {
+		return errors.Annotatef(err, "file for resource %q", name)
+	}
+	if err != nil {
+		return errors.Annotatef(err, "can't read file for resource %q", name)
+	}
+	f, err := d.osOpen(path)
+	if err != nil {
+		return errors.Annotatef(err, "can't read file for resource %q", name)
+	}
+	f.Close()
+	return nil
+}
+
 func (d deployUploader) validateResources() error {
 	var errs []error
 	for _, meta := range d.resources {
@@ -141,6 +469,22 @@ func (d deployUploader) validateResources() error {
 			errs = append(errs, err)
 		}
 	}
+	return combineErrors(errs)
+}
+
+// sortedNames returns the resource names in files, in order.
+func sortedNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// combineErrors returns nil if errs is empty, the only error if there
+// is just one, or a NotValid error joining all their messages.
+func combineErrors(errs []error) error {
 	if len(errs) == 1 {
 		return errors.Trace(errs[0])
 	}
@@ -177,22 +521,82 @@ func (d deployUploader) storeResources(uploads map[string]string, revisions map[
 	return resources
 }
 
-func (d deployUploader) uploadFile(resourcename, filename string) (id string, err error) {
+// storeResourcesByChannel groups the given store resources by the
+// channel they should be resolved from.
+func (d deployUploader) storeResourcesByChannel(resources []charmresource.Resource) map[csparams.Channel][]charmresource.Resource {
+	result := make(map[csparams.Channel][]charmresource.Resource)
+	for _, res := range resources {
+		channel, ok := d.channels[res.Name]
+		if !ok {
+			channel = d.chID.Channel
+		}
+		result[channel] = append(result[channel], res)
+	}
+	return result
+}
+
+// sortedChannels returns the channels in byChannel, in order.
+func sortedChannels(byChannel map[csparams.Channel][]charmresource.Resource) []csparams.Channel {
+	names := make([]string, 0, len(byChannel))
+	for channel := range byChannel {
+		names = append(names, string(channel))
+	}
+	sort.Strings(names)
+	channels := make([]csparams.Channel, len(names))
+	for i, name := range names {
+		channels[i] = csparams.Channel(name)
+	}
+	return channels
+}
+
+func (d deployUploader) uploadFile(resourcename, filename string) (DeployResult, error) {
 	f, err := d.osOpen(filename)
 	if err != nil {
-		return "", errors.Trace(err)
+		return DeployResult{}, errors.Trace(err)
 	}
 	defer f.Close()
+	size, err := f.Seek(0, os.SEEK_END)
+	if err != nil {
+		return DeployResult{}, errors.Trace(err)
+	}
+	if _, err := f.Seek(0, os.SEEK_SET); err != nil {
+		return DeployResult{}, errors.Trace(err)
+	}
 	res := charmresource.Resource{
 		Meta:   d.resources[resourcename],
 		Origin: charmresource.OriginUpload,
 	}
 
-	id, err = d.client.AddPendingResource(d.serviceID, res, filename, f)
+	var r io.ReadSeeker = f
+	if d.ctx != nil {
+		r = contextReader{ctx: d.ctx, ReadSeeker: f}
+	}
+	id, err := d.client.AddPendingResource(d.serviceID, res, filename, r)
 	if err != nil {
-		return "", errors.Trace(err)
+		return DeployResult{}, errors.Trace(err)
 	}
-	return id, err
+	return DeployResult{
+		Name:      resourcename,
+		PendingID: id,
+		Origin:    charmresource.OriginUpload,
+		Revision:  -1,
+		Size:      size,
+	}, nil
+}
+
+// contextReader is an io.ReadSeeker that fails reads once its context
+// is cancelled, so that an upload in progress is aborted.
+type contextReader struct {
+	io.ReadSeeker
+	ctx context.Context
+}
+
+// Read implements io.Reader.
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, errors.Annotate(err, "upload aborted")
+	}
+	return r.ReadSeeker.Read(p)
 }
 
 func (d deployUploader) checkExpectedResources(filenames map[string]string, revisions map[string]int) error {
@@ -207,6 +611,13 @@ func (d deployUploader) checkExpectedResources(filenames map[string]string, revi
 			unknown = append(unknown, name)
 		}
 	}
+	for name := range d.channels {
+		if _, ok := d.resources[name]; !ok {
+			unknown = append(unknown, name)
+		} else if _, ok := filenames[name]; ok {
+			return errors.Errorf("channel specified for resource %q uploaded from a file", name)
+		}
+	}
 	if len(unknown) == 1 {
 		return errors.Errorf("unrecognized resource %q", unknown[0])
 	}
@@ -4,16 +4,24 @@
 package cmd
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
+	"golang.org/x/net/context"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/charm.v6-unstable"
 	charmresource "gopkg.in/juju/charm.v6-unstable/resource"
+	csparams "gopkg.in/juju/charmrepo.v2-unstable/csclient/params"
 	"gopkg.in/macaroon.v1"
 
 	"github.com/juju/juju/charmstore"
@@ -112,14 +120,15 @@ func (s DeploySuite) TestUploadFilesOnly(c *gc.C) {
 		"upload": "foobar.txt",
 	}
 	revisions := map[string]int{}
-	ids, err := du.upload(files, revisions)
+	results, err := du.upload(files, revisions)
 	c.Assert(err, jc.ErrorIsNil)
+	ids := pendingIDs(results)
 	c.Check(ids, gc.DeepEquals, map[string]string{
 		"upload": "id-upload",
 		"store":  "id-store",
 	})
 
-	s.stub.CheckCallNames(c, "Stat", "AddPendingResources", "Open", "AddPendingResource")
+	s.stub.CheckCallNames(c, "Stat", "Open", "AddPendingResources", "Open", "AddPendingResource")
 	expectedStore := []charmresource.Resource{
 		{
 			Meta:     du.resources["store"],
@@ -127,14 +136,14 @@ func (s DeploySuite) TestUploadFilesOnly(c *gc.C) {
 			Revision: -1,
 		},
 	}
-	s.stub.CheckCall(c, 1, "AddPendingResources", "mysql", chID, csMac, expectedStore)
-	s.stub.CheckCall(c, 2, "Open", "foobar.txt")
+	s.stub.CheckCall(c, 2, "AddPendingResources", "mysql", chID, csMac, expectedStore)
+	s.stub.CheckCall(c, 3, "Open", "foobar.txt")
 
 	expectedUpload := charmresource.Resource{
 		Meta:   du.resources["upload"],
 		Origin: charmresource.OriginUpload,
 	}
-	s.stub.CheckCall(c, 3, "AddPendingResource", "mysql", expectedUpload, "foobar.txt", deps.ReadSeekCloser)
+	s.stub.CheckCall(c, 4, "AddPendingResource", "mysql", expectedUpload, "foobar.txt", deps.ReadSeekCloser)
 }
 
 func (s DeploySuite) TestUploadRevisionsOnly(c *gc.C) {
@@ -169,8 +178,9 @@ func (s DeploySuite) TestUploadRevisionsOnly(c *gc.C) {
 	revisions := map[string]int{
 		"store": 3,
 	}
-	ids, err := du.upload(files, revisions)
+	results, err := du.upload(files, revisions)
 	c.Assert(err, jc.ErrorIsNil)
+	ids := pendingIDs(results)
 	c.Check(ids, gc.DeepEquals, map[string]string{
 		"upload": "id-upload",
 		"store":  "id-store",
@@ -223,14 +233,15 @@ func (s DeploySuite) TestUploadFilesAndRevisions(c *gc.C) {
 	revisions := map[string]int{
 		"store": 3,
 	}
-	ids, err := du.upload(files, revisions)
+	results, err := du.upload(files, revisions)
 	c.Assert(err, jc.ErrorIsNil)
+	ids := pendingIDs(results)
 	c.Check(ids, gc.DeepEquals, map[string]string{
 		"upload": "id-upload",
 		"store":  "id-store",
 	})
 
-	s.stub.CheckCallNames(c, "Stat", "AddPendingResources", "Open", "AddPendingResource")
+	s.stub.CheckCallNames(c, "Stat", "Open", "AddPendingResources", "Open", "AddPendingResource")
 	expectedStore := []charmresource.Resource{
 		{
 			Meta:     du.resources["store"],
@@ -238,14 +249,358 @@ func (s DeploySuite) TestUploadFilesAndRevisions(c *gc.C) {
 			Revision: 3,
 		},
 	}
-	s.stub.CheckCall(c, 1, "AddPendingResources", "mysql", chID, csMac, expectedStore)
-	s.stub.CheckCall(c, 2, "Open", "foobar.txt")
+	s.stub.CheckCall(c, 2, "AddPendingResources", "mysql", chID, csMac, expectedStore)
+	s.stub.CheckCall(c, 3, "Open", "foobar.txt")
 
 	expectedUpload := charmresource.Resource{
 		Meta:   du.resources["upload"],
 		Origin: charmresource.OriginUpload,
 	}
-	s.stub.CheckCall(c, 3, "AddPendingResource", "mysql", expectedUpload, "foobar.txt", deps.ReadSeekCloser)
+	s.stub.CheckCall(c, 4, "AddPendingResource", "mysql", expectedUpload, "foobar.txt", deps.ReadSeekCloser)
+}
+
+func (s DeploySuite) TestUploadResults(c *gc.C) {
+	deps := uploadDeps{s.stub, readerCloser{bytes.NewReader([]byte("spamspam"))}}
+	du := deployUploader{
+		serviceID: "mysql",
+		chID: charmstore.CharmID{
+			URL: charm.MustParseURL("cs:~a-user/trusty/spam-5"),
+		},
+		client: deps,
+		resources: map[string]charmresource.Meta{
+			"upload": {
+				Name: "upload",
+				Type: charmresource.TypeFile,
+				Path: "upload",
+			},
+			"store": {
+				Name: "store",
+				Type: charmresource.TypeFile,
+				Path: "store",
+			},
+			"latest": {
+				Name: "latest",
+				Type: charmresource.TypeFile,
+				Path: "latest",
+			},
+		},
+		osOpen: deps.Open,
+		osStat: deps.Stat,
+	}
+
+	results, err := du.upload(
+		map[string]string{"upload": "foobar.txt"},
+		map[string]int{"store": 3},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(results, jc.DeepEquals, map[string]DeployResult{
+		"upload": {
+			Name:      "upload",
+			PendingID: "id-upload",
+			Origin:    charmresource.OriginUpload,
+			Revision:  -1,
+			Size:      8,
+		},
+		"store": {
+			Name:      "store",
+			PendingID: "id-store",
+			Origin:    charmresource.OriginStore,
+			Revision:  3,
+		},
+		"latest": {
+			Name:      "latest",
+			PendingID: "id-latest",
+			Origin:    charmresource.OriginStore,
+			Revision:  7,
+		},
+	})
+}
+
+func (s DeploySuite) TestUploadCancelled(c *gc.C) {
+	deps := uploadDeps{s.stub, rsc{&bytes.Buffer{}}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	du := deployUploader{
+		serviceID: "mysql",
+		client:    deps,
+		resources: map[string]charmresource.Meta{
+			"upload": {
+				Name: "upload",
+				Type: charmresource.TypeFile,
+				Path: "upload",
+			},
+			"store": {
+				Name: "store",
+				Type: charmresource.TypeFile,
+				Path: "store",
+			},
+		},
+		osOpen: deps.Open,
+		osStat: deps.Stat,
+		ctx:    ctx,
+	}
+
+	_, err := du.upload(map[string]string{"upload": "foobar.txt"}, nil)
+	c.Assert(err, gc.ErrorMatches, "deploying resources: context canceled")
+	s.stub.CheckCallNames(c, "Stat", "Open")
+}
+
+func (s DeploySuite) TestUploadFailureRemovesPending(c *gc.C) {
+	deps := uploadDeps{s.stub, rsc{&bytes.Buffer{}}}
+	du := deployUploader{
+		serviceID: "mysql",
+		client:    deps,
+		resources: map[string]charmresource.Meta{
+			"upload": {
+				Name: "upload",
+				Type: charmresource.TypeFile,
+				Path: "upload",
+			},
+			"store": {
+				Name: "store",
+				Type: charmresource.TypeFile,
+				Path: "store",
+			},
+		},
+		osOpen: deps.Open,
+		osStat: deps.Stat,
+	}
+	failure := errors.New("<failure>")
+	s.stub.SetErrors(nil, nil, nil, nil, failure)
+
+	_, err := du.upload(map[string]string{"upload": "foobar.txt"}, nil)
+	c.Assert(errors.Cause(err), gc.Equals, failure)
+
+	s.stub.CheckCallNames(c,
+		"Stat",
+		"Open",
+		"AddPendingResources",
+		"Open",
+		"AddPendingResource",
+		"RemovePendingResources",
+	)
+	s.stub.CheckCall(c, 5, "RemovePendingResources", "mysql", map[string]string{"store": "id-store"})
+}
+
+func (s DeploySuite) TestUploadCancelledRemovesPending(c *gc.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	deps := cancellingDeps{
+		uploadDeps: uploadDeps{s.stub, rsc{&bytes.Buffer{}}},
+		cancel:     cancel,
+	}
+	du := deployUploader{
+		serviceID: "mysql",
+		client:    deps,
+		resources: map[string]charmresource.Meta{
+			"upload": {
+				Name: "upload",
+				Type: charmresource.TypeFile,
+				Path: "upload",
+			},
+			"store": {
+				Name: "store",
+				Type: charmresource.TypeFile,
+				Path: "store",
+			},
+		},
+		osOpen: deps.Open,
+		osStat: deps.Stat,
+		ctx:    ctx,
+	}
+
+	_, err := du.upload(map[string]string{"upload": "foobar.txt"}, nil)
+	c.Assert(err, gc.ErrorMatches, "deploying resources: context canceled")
+
+	s.stub.CheckCallNames(c,
+		"Stat",
+		"Open",
+		"AddPendingResources",
+		"RemovePendingResources",
+	)
+	s.stub.CheckCall(c, 3, "RemovePendingResources", "mysql", map[string]string{"store": "id-store"})
+}
+
+func (s DeploySuite) TestContextReaderAbortsRead(c *gc.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := contextReader{ctx: ctx, ReadSeeker: bytes.NewReader([]byte("spamspam"))}
+	buf := make([]byte, 4)
+	n, err := r.Read(buf)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(buf[:n]), gc.Equals, "spam")
+
+	cancel()
+	_, err = r.Read(buf)
+	c.Assert(err, gc.ErrorMatches, "upload aborted: context canceled")
+}
+
+func (s DeploySuite) TestUploadReportsProgress(c *gc.C) {
+	deps := uploadDeps{s.stub, rsc{&bytes.Buffer{}}}
+	var progress bytes.Buffer
+	du := deployUploader{
+		serviceID: "mysql",
+		client:    deps,
+		resources: map[string]charmresource.Meta{
+			"upload": {
+				Name: "upload",
+				Type: charmresource.TypeFile,
+				Path: "upload",
+			},
+			"store": {
+				Name: "store",
+				Type: charmresource.TypeFile,
+				Path: "store",
+			},
+			"latest": {
+				Name: "latest",
+				Type: charmresource.TypeFile,
+				Path: "latest",
+			},
+		},
+		osOpen:   deps.Open,
+		osStat:   deps.Stat,
+		progress: &progress,
+	}
+
+	files := map[string]string{
+		"upload": "foobar.txt",
+	}
+	revisions := map[string]int{
+		"store": 3,
+	}
+	_, err := du.upload(files, revisions)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(progress.String(), gc.Equals, `
+uploading resource "upload" from foobar.txt
+resources uploaded from files: upload
+resources from the charm store: latest, store (revision 3)
+`[1:])
+}
+
+func (s DeploySuite) TestUploadPerResourceChannel(c *gc.C) {
+	deps := uploadDeps{s.stub, rsc{&bytes.Buffer{}}}
+	chID := charmstore.CharmID{
+		URL:     charm.MustParseURL("cs:~a-user/trusty/spam-5"),
+		Channel: csparams.DevelopmentChannel,
+	}
+	csMac := &macaroon.Macaroon{}
+	du := deployUploader{
+		serviceID: "mysql",
+		chID:      chID,
+		csMac:     csMac,
+		client:    deps,
+		resources: map[string]charmresource.Meta{
+			"pinned": {
+				Name: "pinned",
+				Type: charmresource.TypeFile,
+				Path: "pinned",
+			},
+			"tracking": {
+				Name: "tracking",
+				Type: charmresource.TypeFile,
+				Path: "tracking",
+			},
+		},
+		channels: map[string]csparams.Channel{
+			"pinned": csparams.StableChannel,
+		},
+		osOpen: deps.Open,
+		osStat: deps.Stat,
+	}
+
+	results, err := du.upload(map[string]string{}, map[string]int{})
+	c.Assert(err, jc.ErrorIsNil)
+	ids := pendingIDs(results)
+	c.Check(ids, gc.DeepEquals, map[string]string{
+		"pinned":   "id-pinned",
+		"tracking": "id-tracking",
+	})
+
+	s.stub.CheckCallNames(c, "AddPendingResources", "AddPendingResources")
+	s.stub.CheckCall(c, 0, "AddPendingResources", "mysql", chID, csMac, []charmresource.Resource{{
+		Meta:     du.resources["tracking"],
+		Origin:   charmresource.OriginStore,
+		Revision: -1,
+	}})
+	stableID := chID
+	stableID.Channel = csparams.StableChannel
+	s.stub.CheckCall(c, 1, "AddPendingResources", "mysql", stableID, csMac, []charmresource.Resource{{
+		Meta:     du.resources["pinned"],
+		Origin:   charmresource.OriginStore,
+		Revision: -1,
+	}})
+}
+
+func (s DeploySuite) TestUploadExpiredMacaroon(c *gc.C) {
+	deps := uploadDeps{s.stub, rsc{&bytes.Buffer{}}}
+	csMac, err := macaroon.New([]byte("root-key"), "id", "charmstore")
+	c.Assert(err, jc.ErrorIsNil)
+	err = csMac.AddFirstPartyCaveat("time-before 2016-01-01T00:00:00Z")
+	c.Assert(err, jc.ErrorIsNil)
+	du := deployUploader{
+		serviceID: "mysql",
+		chID:      charmstore.CharmID{URL: charm.MustParseURL("cs:~a-user/trusty/mysql-5")},
+		csMac:     csMac,
+		client:    deps,
+		resources: map[string]charmresource.Meta{
+			"store": {
+				Name: "store",
+				Type: charmresource.TypeFile,
+				Path: "store",
+			},
+			"upload": {
+				Name: "upload",
+				Type: charmresource.TypeFile,
+				Path: "upload",
+			},
+		},
+		osOpen: deps.Open,
+		osStat: deps.Stat,
+	}
+
+	_, err = du.upload(map[string]string{"upload": "foobar.txt"}, map[string]int{})
+	c.Check(err, gc.ErrorMatches, `charm store authentication failed: macaroon expired at 2016-01-01T00:00:00Z`)
+	s.stub.CheckCallNames(c, "Stat", "Open")
+}
+
+func (s DeploySuite) TestCheckMacaroon(c *gc.C) {
+	now := time.Date(2016, 6, 1, 0, 0, 0, 0, time.UTC)
+	c.Check(checkMacaroon(nil, now), jc.ErrorIsNil)
+	c.Check(checkMacaroon(&macaroon.Macaroon{}, now), jc.ErrorIsNil)
+
+	m, err := macaroon.New([]byte("root-key"), "id", "charmstore")
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.AddFirstPartyCaveat("declared username bob")
+	c.Assert(err, jc.ErrorIsNil)
+	err = m.AddFirstPartyCaveat("time-before 2016-06-02T00:00:00Z")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(checkMacaroon(m, now), jc.ErrorIsNil)
+	c.Check(checkMacaroon(m, now.Add(48*time.Hour)), gc.ErrorMatches,
+		`charm store authentication failed: macaroon expired at 2016-06-02T00:00:00Z`)
+}
+
+func (s DeploySuite) TestUploadChannelForFileFails(c *gc.C) {
+	deps := uploadDeps{s.stub, rsc{&bytes.Buffer{}}}
+	du := deployUploader{
+		serviceID: "mysql",
+		client:    deps,
+		resources: map[string]charmresource.Meta{
+			"res1": {
+				Name: "res1",
+				Type: charmresource.TypeFile,
+				Path: "path",
+			},
+		},
+		channels: map[string]csparams.Channel{
+			"res1": csparams.StableChannel,
+		},
+		osOpen: deps.Open,
+		osStat: deps.Stat,
+	}
+
+	_, err := du.upload(map[string]string{"res1": "foobar.txt"}, map[string]int{})
+	c.Check(err, gc.ErrorMatches, `channel specified for resource "res1" uploaded from a file`)
+	s.stub.CheckNoCalls(c)
 }
 
 func (s DeploySuite) TestUploadUnexpectedResourceFile(c *gc.C) {
@@ -322,22 +677,171 @@ func (s DeploySuite) TestMissingResource(c *gc.C) {
 	c.Check(errors.Cause(err), jc.Satisfies, os.IsNotExist)
 }
 
+func (s DeploySuite) TestBadFilesAllReported(c *gc.C) {
+	deps := uploadDeps{s.stub, rsc{&bytes.Buffer{}}}
+	du := deployUploader{
+		serviceID: "mysql",
+		client:    deps,
+		resources: map[string]charmresource.Meta{
+			"res1": {
+				Name: "res1",
+				Type: charmresource.TypeFile,
+				Path: "path1",
+			},
+			"res2": {
+				Name: "res2",
+				Type: charmresource.TypeFile,
+				Path: "path2",
+			},
+			"res3": {
+				Name: "res3",
+				Type: charmresource.TypeFile,
+				Path: "path3",
+			},
+		},
+		osOpen: deps.Open,
+		osStat: deps.Stat,
+	}
+
+	// res1 is missing, res2 can't be opened and res3 is fine.
+	s.stub.SetErrors(os.ErrNotExist, nil, os.ErrPermission)
+
+	files := map[string]string{
+		"res1": "foo.txt",
+		"res2": "bar.txt",
+		"res3": "baz.txt",
+	}
+	_, err := du.upload(files, map[string]int{})
+	c.Check(err, gc.ErrorMatches, `file for resource "res1": file does not exist, can't read file for resource "res2": permission denied`)
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+
+	s.stub.CheckCallNames(c, "Stat", "Stat", "Open", "Stat", "Open")
+}
+
+func (s DeploySuite) TestArchiveResourceDirs(c *gc.C) {
+	dir := filepath.Join(c.MkDir(), "site")
+	err := os.MkdirAll(filepath.Join(dir, "static"), 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, "static", "index.html"), []byte("<html/>"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+	file := filepath.Join(c.MkDir(), "data.zip")
+	err = ioutil.WriteFile(file, []byte("zip"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	filenames, cleanup, err := archiveResourceDirs(map[string]string{
+		"site": dir,
+		"data": file,
+	}, map[string]charmresource.Meta{
+		"site": {Name: "site", Type: charmresource.TypeFile, Path: "site.tar.gz"},
+		"data": {Name: "data", Type: charmresource.TypeFile, Path: "data.zip"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(filenames["data"], gc.Equals, file)
+	archive := filenames["site"]
+	c.Check(archive, gc.Matches, `.*/site-.*\.tar\.gz`)
+
+	f, err := os.Open(archive)
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	c.Assert(err, jc.ErrorIsNil)
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		names = append(names, strings.TrimSuffix(hdr.Name, "/"))
+	}
+	c.Check(names, jc.SameContents, []string{"site", "site/static", "site/static/index.html"})
+
+	cleanup()
+	_, err = os.Stat(archive)
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s DeploySuite) TestArchiveResourceDirsUsesResourceExtension(c *gc.C) {
+	dir := filepath.Join(c.MkDir(), "site")
+	err := os.Mkdir(dir, 0755)
+	c.Assert(err, jc.ErrorIsNil)
+	err = ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("<html/>"), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	filenames, cleanup, err := archiveResourceDirs(map[string]string{
+		"compressed": dir,
+		"plain":      dir,
+	}, map[string]charmresource.Meta{
+		"compressed": {Name: "compressed", Type: charmresource.TypeFile, Path: "site.tgz"},
+		"plain":      {Name: "plain", Type: charmresource.TypeFile, Path: "site.tar"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	defer cleanup()
+	c.Check(filenames["compressed"], gc.Matches, `.*/site-.*\.tgz`)
+	c.Check(filenames["plain"], gc.Matches, `.*/site-.*\.tar`)
+
+	// Only the .tgz tarball is gzipped.
+	f, err := os.Open(filenames["plain"])
+	c.Assert(err, jc.ErrorIsNil)
+	defer f.Close()
+	hdr, err := tar.NewReader(f).Next()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(strings.TrimSuffix(hdr.Name, "/"), gc.Equals, "site")
+}
+
+func (s DeploySuite) TestArchiveResourceDirsNotTarball(c *gc.C) {
+	dir := c.MkDir()
+	_, _, err := archiveResourceDirs(map[string]string{
+		"data": dir,
+	}, map[string]charmresource.Meta{
+		"data": {Name: "data", Type: charmresource.TypeFile, Path: "data.zip"},
+	})
+	c.Check(err, gc.ErrorMatches, `cannot upload directory ".*" for resource "data": expected a "data.zip" file, only .tar, .tar.gz and .tgz resources can be uploaded from a directory`)
+}
+
+func (s DeploySuite) TestDeployResourcesChecksBeforeArchiving(c *gc.C) {
+	tempDir := c.MkDir()
+	s.PatchEnvironment("TMPDIR", tempDir)
+	dir := filepath.Join(c.MkDir(), "site")
+	err := os.Mkdir(dir, 0755)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = DeployResources(DeployResourcesArgs{
+		ServiceID: "mysql",
+		Filenames: map[string]string{"site": dir},
+	})
+	c.Assert(err, gc.ErrorMatches, `unrecognized resource "site"`)
+
+	// No tarball was made of the directory.
+	entries, err := ioutil.ReadDir(tempDir)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(entries, gc.HasLen, 0)
+}
+
 type uploadDeps struct {
 	stub           *testing.Stub
 	ReadSeekCloser ReadSeekCloser
 }
 
-func (s uploadDeps) AddPendingResources(serviceID string, charmID charmstore.CharmID, csMac *macaroon.Macaroon, resources []charmresource.Resource) (ids []string, err error) {
+func (s uploadDeps) AddPendingResources(serviceID string, charmID charmstore.CharmID, csMac *macaroon.Macaroon, resources []charmresource.Resource) (ids []string, revisions []int, err error) {
 	charmresource.Sort(resources)
 	s.stub.AddCall("AddPendingResources", serviceID, charmID, csMac, resources)
 	if err := s.stub.NextErr(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	ids = make([]string, len(resources))
+	revisions = make([]int, len(resources))
 	for i, res := range resources {
 		ids[i] = "id-" + res.Name
+		// The controller resolves unpinned resources to the
+		// latest revision in the store.
+		revisions[i] = res.Revision
+		if revisions[i] < 0 {
+			revisions[i] = 7
+		}
 	}
-	return ids, nil
+	return ids, revisions, nil
 }
 
 func (s uploadDeps) AddPendingResource(serviceID string, resource charmresource.Resource, filename string, r io.ReadSeeker) (id string, err error) {
@@ -348,6 +852,11 @@ func (s uploadDeps) AddPendingResource(serviceID string, resource charmresource.
 	return "id-" + resource.Name, nil
 }
 
+func (s uploadDeps) RemovePendingResources(serviceID string, pendingIDs map[string]string) error {
+	s.stub.AddCall("RemovePendingResources", serviceID, pendingIDs)
+	return s.stub.NextErr()
+}
+
 func (s uploadDeps) Open(name string) (ReadSeekCloser, error) {
 	s.stub.AddCall("Open", name)
 	if err := s.stub.NextErr(); err != nil {
@@ -361,6 +870,18 @@ func (s uploadDeps) Stat(name string) error {
 	return s.stub.NextErr()
 }
 
+// cancellingDeps cancels the deploy once the store resources have
+// been added as pending.
+type cancellingDeps struct {
+	uploadDeps
+	cancel func()
+}
+
+func (s cancellingDeps) AddPendingResources(serviceID string, charmID charmstore.CharmID, csMac *macaroon.Macaroon, resources []charmresource.Resource) ([]string, []int, error) {
+	defer s.cancel()
+	return s.uploadDeps.AddPendingResources(serviceID, charmID, csMac, resources)
+}
+
 type rsc struct {
 	*bytes.Buffer
 }
@@ -371,3 +892,11 @@ func (rsc) Close() error {
 func (rsc) Seek(offset int64, whence int) (int64, error) {
 	return 0, nil
 }
+
+type readerCloser struct {
+	*bytes.Reader
+}
+
+func (readerCloser) Close() error {
+	return nil
+}
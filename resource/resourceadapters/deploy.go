@@ -7,7 +7,9 @@ import (
 	"strconv"
 
 	"github.com/juju/errors"
+	"golang.org/x/net/context"
 	charmresource "gopkg.in/juju/charm.v6-unstable/resource"
+	csparams "gopkg.in/juju/charmrepo.v2-unstable/csclient/params"
 	"gopkg.in/macaroon.v1"
 
 	"github.com/juju/juju/api"
@@ -18,37 +20,78 @@ import (
 
 // DeployResources uploads the bytes for the given files to the server and
 // creates pending resource metadata for the all resource mentioned in the
-// metadata. It returns a map of resource name to pending resource IDs.
-func DeployResources(serviceID string, chID charmstore.CharmID, csMac *macaroon.Macaroon, filesAndRevisions map[string]string, resources map[string]charmresource.Meta, conn api.Connection) (ids map[string]string, err error) {
+// metadata. Store resources are resolved from the charm's channel, unless
+// channels specifies another. It returns a map of resource name to pending
+// resource IDs.
+func DeployResources(serviceID string, chID charmstore.CharmID, csMac *macaroon.Macaroon, filesAndRevisions map[string]string, channels map[string]csparams.Channel, resources map[string]charmresource.Meta, conn api.Connection) (ids map[string]string, err error) {
+	return DeployResourcesWithContext(context.Background(), serviceID, chID, csMac, filesAndRevisions, channels, resources, conn)
+}
+
+// DeployResourcesWithContext does the same as DeployResources, but stops
+// when ctx is cancelled, aborting any upload that is in progress.
+func DeployResourcesWithContext(ctx context.Context, serviceID string, chID charmstore.CharmID, csMac *macaroon.Macaroon, filesAndRevisions map[string]string, channels map[string]csparams.Channel, resources map[string]charmresource.Meta, conn api.Connection) (ids map[string]string, err error) {
 	client, err := newAPIClient(conn)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	filenames := make(map[string]string)
-	revisions := make(map[string]int)
-	for name, val := range filesAndRevisions {
-		rev, err := strconv.Atoi(val)
-		if err != nil {
-			filenames[name] = val
-		} else {
-			revisions[name] = rev
-		}
+	filenames, revisions := splitFilesAndRevisions(filesAndRevisions)
+	ids, err = cmd.DeployResourcesWithContext(ctx, cmd.DeployResourcesArgs{
+		ServiceID:          serviceID,
+		CharmID:            chID,
+		CharmStoreMacaroon: csMac,
+		Filenames:          filenames,
+		Revisions:          revisions,
+		Channels:           channels,
+		ResourcesMeta:      resources,
+		Client:             &deployClient{client},
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
+	return ids, nil
+}
 
-	ids, err = cmd.DeployResources(cmd.DeployResourcesArgs{
+// DeployResourcesWithResults does the same as DeployResources, but
+// returns a cmd.DeployResult for each resource, keyed by resource name,
+// reporting the revision the controller resolved each store resource to.
+func DeployResourcesWithResults(serviceID string, chID charmstore.CharmID, csMac *macaroon.Macaroon, filesAndRevisions map[string]string, channels map[string]csparams.Channel, resources map[string]charmresource.Meta, conn api.Connection) (map[string]cmd.DeployResult, error) {
+	client, err := newAPIClient(conn)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	filenames, revisions := splitFilesAndRevisions(filesAndRevisions)
+	results, err := cmd.DeployResourcesWithResults(cmd.DeployResourcesArgs{
 		ServiceID:          serviceID,
 		CharmID:            chID,
 		CharmStoreMacaroon: csMac,
 		Filenames:          filenames,
 		Revisions:          revisions,
+		Channels:           channels,
 		ResourcesMeta:      resources,
 		Client:             &deployClient{client},
 	})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	return ids, nil
+	return results, nil
+}
+
+// splitFilesAndRevisions splits the values given for resources at the
+// command line into revisions, for those that are numbers, and filenames.
+func splitFilesAndRevisions(filesAndRevisions map[string]string) (filenames map[string]string, revisions map[string]int) {
+	filenames = make(map[string]string)
+	revisions = make(map[string]int)
+	for name, val := range filesAndRevisions {
+		rev, err := strconv.Atoi(val)
+		if err != nil {
+			filenames[name] = val
+		} else {
+			revisions[name] = rev
+		}
+	}
+	return filenames, revisions
 }
 
 type deployClient struct {
@@ -56,8 +99,8 @@ type deployClient struct {
 }
 
 // AddPendingResources adds pending metadata for store-based resources.
-func (cl *deployClient) AddPendingResources(serviceID string, chID charmstore.CharmID, csMac *macaroon.Macaroon, resources []charmresource.Resource) ([]string, error) {
-	return cl.Client.AddPendingResources(client.AddPendingResourcesArgs{
+func (cl *deployClient) AddPendingResources(serviceID string, chID charmstore.CharmID, csMac *macaroon.Macaroon, resources []charmresource.Resource) ([]string, []int, error) {
+	return cl.Client.AddPendingResourcesWithRevisions(client.AddPendingResourcesArgs{
 		ServiceID:          serviceID,
 		CharmID:            chID,
 		CharmStoreMacaroon: csMac,
@@ -56,6 +56,9 @@ type resourcePersistence interface {
 	// NewResolvePendingResourceOps generates mongo transaction operations
 	// to set the identified resource as active.
 	NewResolvePendingResourceOps(resID, pendingID string) ([]txn.Op, error)
+
+	// RemovePendingResource removes the identified pending resource.
+	RemovePendingResource(resID, pendingID string) error
 }
 
 // StagedResource represents resource info that has been added to the
@@ -209,6 +212,18 @@ func (st resourceState) UpdatePendingResource(serviceID, pendingID, userID strin
 
 // TODO(ericsnow) Add ResolvePendingResource().
 
+// RemovePendingResource removes the identified pending resource from the
+// model, along with its data. It is not an error if the pending resource
+// does not exist.
+func (st resourceState) RemovePendingResource(serviceID, name, pendingID string) error {
+	logger.Debugf("removing pending resource %q for service %q (ID: %s)", name, serviceID, pendingID)
+	id := newResourceID(serviceID, name)
+	if err := st.persist.RemovePendingResource(id, pendingID); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
 func (st resourceState) setResource(pendingID, serviceID, userID string, chRes charmresource.Resource, r io.Reader) (resource.Resource, error) {
 	id := newResourceID(serviceID, chRes.Name)
 
@@ -378,6 +378,17 @@ func (s *ResourceSuite) TestUpdatePendingResourceOkay(c *gc.C) {
 	})
 }
 
+func (s *ResourceSuite) TestRemovePendingResource(c *gc.C) {
+	st := NewState(s.raw)
+	s.stub.ResetCalls()
+
+	err := st.RemovePendingResource("a-service", "spam", "some-unique-id")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "RemovePendingResource")
+	s.stub.CheckCall(c, 0, "RemovePendingResource", "a-service/spam", "some-unique-id")
+}
+
 func (s *ResourceSuite) TestAddPendingResourceOkay(c *gc.C) {
 	s.pendingID = "some-unique-ID-001"
 	expected := newUploadResource(c, "spam", "spamspamspam")
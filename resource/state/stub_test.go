@@ -161,6 +161,15 @@ func (s *stubPersistence) NewResolvePendingResourceOps(resID, pendingID string)
 	return ops, nil
 }
 
+func (s *stubPersistence) RemovePendingResource(resID, pendingID string) error {
+	s.stub.AddCall("RemovePendingResource", resID, pendingID)
+	if err := s.stub.NextErr(); err != nil {
+		return errors.Trace(err)
+	}
+
+	return nil
+}
+
 type stubStagedResource struct {
 	stub *testing.Stub
 }
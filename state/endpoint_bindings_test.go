@@ -81,9 +81,9 @@ peers:
 
 	// Add some spaces to use in bindings, but notably NOT the default space, as
 	// it should be always allowed.
-	_, err := s.State.AddSpace("client", "", nil, true)
+	_, err := s.State.AddSpace("client", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("apps", "", nil, false)
+	_, err = s.State.AddSpace("apps", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 }
 
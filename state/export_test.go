@@ -461,6 +461,10 @@ func SpaceDoc(s *Space) spaceDoc {
 	return s.doc
 }
 
+func SubnetRefCount(s *Subnet) int {
+	return s.doc.RefCount
+}
+
 func ForceDestroyMachineOps(m *Machine) ([]txn.Op, error) {
 	return m.forceDestroyOps()
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
@@ -689,3 +690,84 @@ func (s *ipAddressesStateSuite) testMachineSetDevicesAddressesIdempotently(c *gc
 		}
 	}
 }
+
+func (s *ipAddressesStateSuite) TestAddSpaceFailsWithSubnetInUse(c *gc.C) {
+	_, addresses := s.addNamedDeviceWithAddresses(c, "eth0", "0.1.2.3/24")
+
+	_, err := s.State.AddSpace("my-space", "", []string{"0.1.2.0/24"}, false, nil)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		`adding space "my-space": subnet "0.1.2.0/24" is in use by machines %s`, s.machine.Id(),
+	))
+	_, err = s.State.Space("my-space")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = addresses[0].Remove()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSpace("my-space", "", []string{"0.1.2.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ipAddressesStateSuite) TestRenameSpaceFailsWithSubnetInUse(c *gc.C) {
+	_, err := s.State.AddSpace("my-space", "", []string{"0.1.2.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	device, _ := s.addNamedDeviceWithAddresses(c, "eth0", "0.1.2.3/24", "0.1.2.4/24")
+
+	err = s.State.RenameSpace("my-space", "new-space")
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		`renaming space "my-space" to "new-space": subnet "0.1.2.0/24" is in use by machines %s`, s.machine.Id(),
+	))
+
+	err = device.RemoveAddresses()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.RenameSpace("my-space", "new-space")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ipAddressesStateSuite) TestSubnetRefCountFollowsAddresses(c *gc.C) {
+	device, _ := s.addNamedDeviceWithAddresses(c, "eth0", "0.1.2.3/24", "10.20.1.1/16")
+	s.assertSubnetRefCount(c, "0.1.2.0/24", 1)
+	s.assertSubnetRefCount(c, "10.20.0.0/16", 1)
+
+	err := s.machine.RemoveAllAddresses()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSubnetRefCount(c, "0.1.2.0/24", 0)
+	s.assertSubnetRefCount(c, "10.20.0.0/16", 0)
+
+	err = s.machine.SetDevicesAddresses(state.LinkLayerDeviceAddress{
+		DeviceName:   device.Name(),
+		ConfigMethod: state.StaticAddress,
+		CIDRAddress:  "0.1.2.5/24",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSubnetRefCount(c, "0.1.2.0/24", 1)
+}
+
+func (s *ipAddressesStateSuite) TestAddSubnetCountsExistingAddresses(c *gc.C) {
+	s.addNamedDeviceWithAddresses(c, "eth0", "192.168.1.5/24", "192.168.1.6/24")
+
+	_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "192.168.1.0/24"})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSubnetRefCount(c, "192.168.1.0/24", 2)
+}
+
+func (s *ipAddressesStateSuite) TestRemoveUncountedAddressClampsSubnetRefCount(c *gc.C) {
+	s.addNamedDeviceWithAddresses(c, "eth0", "0.1.2.3/24", "0.1.2.4/24")
+
+	// Simulate addresses added before reference counting was introduced.
+	subnets, closer := state.GetCollection(s.State, "subnets")
+	defer closer()
+	err := subnets.Writeable().UpdateId("0.1.2.0/24", bson.D{{"$unset", bson.D{{"ref-count", ""}}}})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSubnetRefCount(c, "0.1.2.0/24", 0)
+
+	err = s.machine.RemoveAllAddresses()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertNoAddressesOnMachine(c, s.machine)
+	s.assertSubnetRefCount(c, "0.1.2.0/24", 0)
+}
+
+func (s *ipAddressesStateSuite) assertSubnetRefCount(c *gc.C, cidr string, expected int) {
+	subnet, err := s.State.Subnet(cidr)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(state.SubnetRefCount(subnet), gc.Equals, expected)
+}
@@ -425,9 +425,8 @@ func (client *client) expireLeaseOps(name string) ([]txn.Op, error) {
 
 	// We also can't expire a lease whose expiry time may be in the future.
 	skew := client.skews[lastEntry.writer]
-	latestExpiry := skew.Latest(lastEntry.expiry)
 	now := client.config.Clock.Now()
-	if !now.After(latestExpiry) {
+	if !skew.Expired(lastEntry.expiry, now) {
 		return nil, errors.Annotatef(lease.ErrInvalid, "lease %q expires in the future", name)
 	}
 
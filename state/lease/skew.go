@@ -4,23 +4,53 @@
 package lease
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
 )
 
 // Skew holds information about a remote writer's idea of the current time.
+//
+// A Skew can be stored in mongo without conversion, but it does not
+// round-trip exactly: times are stored with millisecond precision, and
+// are read back in the local time zone. Compare the times read back with
+// Equal rather than ==. The fields of a zero Skew are omitted, so that it
+// is still zero when read back.
 type Skew struct {
 
 	// LastWrite is the most recent remote time known to have been written
 	// by the skewed writer.
-	LastWrite time.Time
+	LastWrite time.Time `bson:"last-write,omitempty"`
 
 	// Beginning should be the latest known local time before LastWrite
 	// was read.
-	Beginning time.Time
+	Beginning time.Time `bson:"beginning,omitempty"`
 
 	// End should be the earliest known local time after LastWrite
 	// was read.
-	End time.Time
+	End time.Time `bson:"end,omitempty"`
+}
+
+// NewSkew returns a Skew for the remote time returned by readRemote,
+// bracketing the read with local times taken from the supplied clock.
+func NewSkew(clock clock.Clock, readRemote func() (time.Time, error)) (Skew, error) {
+	beginning := clock.Now()
+	remote, err := readRemote()
+	if err != nil {
+		return Skew{}, errors.Trace(err)
+	}
+	end := clock.Now()
+	skew := Skew{
+		LastWrite: remote,
+		Beginning: beginning,
+		End:       end,
+	}
+	if err := skew.Validate(); err != nil {
+		return Skew{}, errors.Trace(err)
+	}
+	return skew, nil
 }
 
 // Earliest returns the earliest local time after which we can be confident
@@ -43,6 +73,85 @@ func (skew Skew) Latest(remote time.Time) (local time.Time) {
 	return skew.End.Add(delta)
 }
 
+// Expired returns true only if localNow is after Latest(remoteDeadline):
+// that is, if we can be confident that the remote writer will agree the
+// deadline has passed. For a zero skew, that's just when localNow is
+// after remoteDeadline.
+func (skew Skew) Expired(remoteDeadline, localNow time.Time) bool {
+	return localNow.After(skew.Latest(remoteDeadline))
+}
+
+// Combine returns a Skew for a writer whose times reach us through an
+// intermediary: skew describes the intermediary's clock relative to the
+// local one, and other describes the writer's clock relative to the
+// intermediary's. The read window of the result is as wide as both
+// windows together, so its Earliest and Latest account conservatively
+// for the uncertainty of both layers. Combining with a zero skew returns
+// the other skew unchanged.
+func (skew Skew) Combine(other Skew) Skew {
+	if other.isZero() {
+		return skew
+	}
+	if skew.isZero() {
+		return other
+	}
+	return Skew{
+		LastWrite: other.LastWrite,
+		Beginning: skew.Earliest(other.Beginning),
+		End:       skew.Latest(other.End),
+	}
+}
+
+// Validate returns an error if the skew's read window is inconsistent:
+// that is, if it ends before it begins, or if LastWrite is set without
+// both ends of the window. The zero Skew is valid.
+func (skew Skew) Validate() error {
+	if skew.isZero() {
+		return nil
+	}
+	if skew.Beginning.IsZero() || skew.End.IsZero() {
+		return errors.New("skew read window not set")
+	}
+	if skew.Beginning.After(skew.End) {
+		return errors.Errorf("skew read window ends (%s) before it begins (%s)", skew.End, skew.Beginning)
+	}
+	return nil
+}
+
+// MaxOffset returns the largest amount by which the remote writer's clock
+// might differ from the local clock, in either direction. It's half the
+// width of the read window plus the distance of LastWrite from the middle
+// of that window; a zero skew has no offset.
+func (skew Skew) MaxOffset() time.Duration {
+	if skew.isZero() {
+		return 0
+	}
+	halfWindow := skew.End.Sub(skew.Beginning) / 2
+	bias := skew.LastWrite.Sub(skew.Beginning.Add(halfWindow))
+	if bias < 0 {
+		bias = -bias
+	}
+	return halfWindow + bias
+}
+
+// skewTimeFormat is used to format the times in a Skew's String.
+const skewTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// String returns a compact description of the skew, for logging: the
+// remote write time, the local read window and the width of that window.
+// A zero skew is described as "zero".
+func (skew Skew) String() string {
+	if skew.isZero() {
+		return "zero"
+	}
+	return fmt.Sprintf("lastWrite=%s window=[%s,%s] span=%s",
+		skew.LastWrite.Format(skewTimeFormat),
+		skew.Beginning.Format(skewTimeFormat),
+		skew.End.Format(skewTimeFormat),
+		skew.End.Sub(skew.Beginning),
+	)
+}
+
 // isZero lets us shortcut Earliest and Latest when the skew represents a
 // perfect unskewed clock (such as for a local writer).
 func (skew Skew) isZero() bool {
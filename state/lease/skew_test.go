@@ -6,8 +6,11 @@ package lease_test
 import (
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/state/lease"
 )
@@ -26,6 +29,7 @@ func (s *SkewSuite) TestZero(c *gc.C) {
 
 	c.Check(skew.Earliest(now), gc.Equals, now)
 	c.Check(skew.Latest(now), gc.Equals, now)
+	c.Check(skew.MaxOffset(), gc.Equals, time.Duration(0))
 }
 
 func (s *SkewSuite) TestApparentPastWrite(c *gc.C) {
@@ -160,3 +164,193 @@ func (s *SkewSuite) TestMixedTimezones(c *gc.C) {
 	// have thought it was before now is one second in the future.
 	c.Check(skew.Latest(now), gc.DeepEquals, oneSecondLater.In(elsewhere))
 }
+
+func (s *SkewSuite) TestMaxOffset(c *gc.C) {
+	now := time.Now()
+	oneSecondAgo := now.Add(-time.Second)
+	threeSecondsAgo := now.Add(-3 * time.Second)
+
+	// Between T-3 and T-1 we read a remote time; the remote clock was
+	// behind ours by between 6 and 8 seconds, or ahead of ours by between
+	// 10 and 12 seconds.
+	behind := lease.Skew{
+		LastWrite: now.Add(-9 * time.Second),
+		Beginning: threeSecondsAgo,
+		End:       oneSecondAgo,
+	}
+	c.Check(behind.MaxOffset(), gc.Equals, 8*time.Second)
+
+	ahead := lease.Skew{
+		LastWrite: now.Add(9 * time.Second),
+		Beginning: threeSecondsAgo,
+		End:       oneSecondAgo,
+	}
+	c.Check(ahead.MaxOffset(), gc.Equals, 12*time.Second)
+}
+
+func (s *SkewSuite) TestCombine(c *gc.C) {
+	now := time.Now()
+
+	// Between T-3 and T-1 local time, we read T+9 from an intermediary.
+	intermediary := lease.Skew{
+		LastWrite: now.Add(9 * time.Second),
+		Beginning: now.Add(-3 * time.Second),
+		End:       now.Add(-time.Second),
+	}
+	// Between T+10 and T+12 intermediary time, it read T-20 from the
+	// writer.
+	writer := lease.Skew{
+		LastWrite: now.Add(-20 * time.Second),
+		Beginning: now.Add(10 * time.Second),
+		End:       now.Add(12 * time.Second),
+	}
+
+	// So the writer wrote T-20 between T-2 and T+2 local time.
+	combined := intermediary.Combine(writer)
+	c.Check(combined, gc.DeepEquals, lease.Skew{
+		LastWrite: now.Add(-20 * time.Second),
+		Beginning: now.Add(-2 * time.Second),
+		End:       now.Add(2 * time.Second),
+	})
+	c.Check(combined.Validate(), jc.ErrorIsNil)
+
+	// Earliest and Latest agree with applying each layer in turn.
+	remote := now.Add(-10 * time.Second)
+	c.Check(combined.Earliest(remote), gc.DeepEquals, intermediary.Earliest(writer.Earliest(remote)))
+	c.Check(combined.Latest(remote), gc.DeepEquals, intermediary.Latest(writer.Latest(remote)))
+}
+
+func (s *SkewSuite) TestCombineZero(c *gc.C) {
+	now := time.Now()
+	skew := lease.Skew{
+		LastWrite: now.Add(-9 * time.Second),
+		Beginning: now.Add(-3 * time.Second),
+		End:       now.Add(-time.Second),
+	}
+	c.Check(skew.Combine(lease.Skew{}), gc.DeepEquals, skew)
+	c.Check(lease.Skew{}.Combine(skew), gc.DeepEquals, skew)
+	c.Check(lease.Skew{}.Combine(lease.Skew{}), gc.DeepEquals, lease.Skew{})
+}
+
+func (s *SkewSuite) TestBSONRoundTrip(c *gc.C) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	skew := lease.Skew{
+		LastWrite: now.Add(-9 * time.Second),
+		Beginning: now.Add(-3 * time.Second),
+		End:       now.Add(-time.Second),
+	}
+	data, err := bson.Marshal(skew)
+	c.Assert(err, jc.ErrorIsNil)
+	var read lease.Skew
+	err = bson.Unmarshal(data, &read)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Times are read back in the local time zone, so they
+	// can only be compared as instants.
+	c.Check(read.LastWrite.Equal(skew.LastWrite), jc.IsTrue)
+	c.Check(read.Beginning.Equal(skew.Beginning), jc.IsTrue)
+	c.Check(read.End.Equal(skew.End), jc.IsTrue)
+}
+
+func (s *SkewSuite) TestBSONRoundTripZero(c *gc.C) {
+	data, err := bson.Marshal(lease.Skew{})
+	c.Assert(err, jc.ErrorIsNil)
+	var raw bson.M
+	err = bson.Unmarshal(data, &raw)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(raw, gc.HasLen, 0)
+
+	var read lease.Skew
+	err = bson.Unmarshal(data, &read)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(read, gc.DeepEquals, lease.Skew{})
+
+	// A zero skew read back from mongo is still unskewed.
+	now := time.Now()
+	c.Check(read.Earliest(now), gc.Equals, now)
+	c.Check(read.MaxOffset(), gc.Equals, time.Duration(0))
+}
+
+func (s *SkewSuite) TestExpired(c *gc.C) {
+	now := time.Now()
+	skew := lease.Skew{
+		LastWrite: now.Add(-9 * time.Second),
+		Beginning: now.Add(-3 * time.Second),
+		End:       now.Add(-time.Second),
+	}
+
+	// The remote deadline 9 seconds ago maps to a latest local time
+	// one second ago, so it has certainly passed...
+	c.Check(skew.Expired(now.Add(-9*time.Second), now), jc.IsTrue)
+
+	// ...but a remote deadline one second later maps to exactly now,
+	// which has not.
+	c.Check(skew.Expired(now.Add(-8*time.Second), now), jc.IsFalse)
+	c.Check(skew.Expired(now, now), jc.IsFalse)
+}
+
+func (s *SkewSuite) TestExpiredZero(c *gc.C) {
+	now := time.Now()
+	skew := lease.Skew{}
+	c.Check(skew.Expired(now.Add(-time.Nanosecond), now), jc.IsTrue)
+	c.Check(skew.Expired(now, now), jc.IsFalse)
+	c.Check(skew.Expired(now.Add(time.Second), now), jc.IsFalse)
+}
+
+func (s *SkewSuite) TestString(c *gc.C) {
+	beginning := time.Date(2016, 5, 4, 12, 0, 0, 0, time.UTC)
+	skew := lease.Skew{
+		LastWrite: beginning.Add(20 * time.Millisecond),
+		Beginning: beginning,
+		End:       beginning.Add(50 * time.Millisecond),
+	}
+	c.Check(skew.String(), gc.Equals, "lastWrite=2016-05-04T12:00:00.020Z "+
+		"window=[2016-05-04T12:00:00.000Z,2016-05-04T12:00:00.050Z] span=50ms")
+}
+
+func (s *SkewSuite) TestStringZero(c *gc.C) {
+	c.Check(lease.Skew{}.String(), gc.Equals, "zero")
+}
+
+func (s *SkewSuite) TestValidate(c *gc.C) {
+	now := time.Now()
+	c.Check(lease.Skew{}.Validate(), jc.ErrorIsNil)
+
+	valid := lease.Skew{
+		LastWrite: now,
+		Beginning: now.Add(-time.Second),
+		End:       now,
+	}
+	c.Check(valid.Validate(), jc.ErrorIsNil)
+
+	inverted := valid
+	inverted.Beginning, inverted.End = valid.End, valid.Beginning
+	c.Check(inverted.Validate(), gc.ErrorMatches, "skew read window ends .* before it begins .*")
+
+	unbounded := lease.Skew{LastWrite: now}
+	c.Check(unbounded.Validate(), gc.ErrorMatches, "skew read window not set")
+}
+
+func (s *SkewSuite) TestNewSkew(c *gc.C) {
+	now := time.Now()
+	clock := NewClock(now, time.Second)
+	remote := now.Add(-time.Minute)
+
+	skew, err := lease.NewSkew(clock, func() (time.Time, error) {
+		return remote, nil
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(skew, gc.DeepEquals, lease.Skew{
+		LastWrite: remote,
+		Beginning: now,
+		End:       now.Add(time.Second),
+	})
+}
+
+func (s *SkewSuite) TestNewSkewReadError(c *gc.C) {
+	clock := NewClock(time.Now(), time.Second)
+	_, err := lease.NewSkew(clock, func() (time.Time, error) {
+		return time.Time{}, errors.New("boom")
+	})
+	c.Check(err, gc.ErrorMatches, "boom")
+}
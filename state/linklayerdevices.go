@@ -473,10 +473,15 @@ func (dev *LinkLayerDevice) Addresses() ([]*Address, error) {
 // RemoveAddresses removes all IP addresses assigned to the device.
 func (dev *LinkLayerDevice) RemoveAddresses() error {
 	findQuery := findAddressesQuery(dev.doc.MachineID, dev.doc.Name)
-	ops, err := dev.st.removeMatchingIPAddressesDocOps(findQuery)
-	if err != nil {
-		return errors.Trace(err)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		ops, err := dev.st.removeMatchingIPAddressesDocOps(findQuery)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(ops) == 0 {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return ops, nil
 	}
-
-	return dev.st.runTransaction(ops)
+	return dev.st.run(buildTxn)
 }
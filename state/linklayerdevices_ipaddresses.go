@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/juju/errors"
+	jujutxn "github.com/juju/txn"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 
@@ -202,13 +203,17 @@ func ipAddressGlobalKey(machineID, deviceName, address string) string {
 func (addr *Address) Remove() (err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot remove %s", addr)
 
-	removeOp := removeIPAddressDocOp(addr.doc.DocID)
-	ops := []txn.Op{removeOp}
-	if addr.ProviderID() != "" {
-		op := addr.st.networkEntityGlobalKeyRemoveOp("address", addr.ProviderID())
-		ops = append(ops, op)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		ops, err := addr.st.removeMatchingIPAddressesDocOps(bson.D{{"_id", addr.doc.DocID}})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(ops) == 0 {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return ops, nil
 	}
-	return addr.st.runTransaction(ops)
+	return addr.st.run(buildTxn)
 }
 
 // removeIPAddressDocOpOp returns an operation to remove the ipAddressDoc
@@ -276,10 +281,17 @@ func updateIPAddressDocOp(existingDoc, newDoc *ipAddressDoc) txn.Op {
 		updates = append(updates, bson.DocElem{Name: "$unset", Value: deletes})
 	}
 
+	var assert interface{} = txn.DocExists
+	if existingDoc.SubnetCIDR != newDoc.SubnetCIDR {
+		// Subnet references are counted, so ensure the
+		// subnet did not change since it was read.
+		assert = bson.D{{"subnet-cidr", existingDoc.SubnetCIDR}}
+	}
+
 	return txn.Op{
 		C:      ipAddressesC,
 		Id:     existingDoc.DocID,
-		Assert: txn.DocExists,
+		Assert: assert,
 		Update: updates,
 	}
 }
@@ -295,15 +307,13 @@ func findAddressesQuery(machineID, deviceName string) bson.D {
 	return query
 }
 
+// removeMatchingIPAddressesDocOps returns the operations needed to remove all
+// IP addresses matching findQuery. Addresses in known subnets are asserted to
+// still exist, as their removal decrements the subnet's reference count.
 func (st *State) removeMatchingIPAddressesDocOps(findQuery bson.D) ([]txn.Op, error) {
-	var ops []txn.Op
+	var docs []ipAddressDoc
 	callbackFunc := func(resultDoc *ipAddressDoc) {
-		ops = append(ops, removeIPAddressDocOp(resultDoc.DocID))
-		if resultDoc.ProviderID != "" {
-			addrID := network.Id(resultDoc.ProviderID)
-			op := st.networkEntityGlobalKeyRemoveOp("address", addrID)
-			ops = append(ops, op)
-		}
+		docs = append(docs, *resultDoc)
 	}
 
 	err := st.forEachIPAddressDoc(findQuery, callbackFunc)
@@ -311,6 +321,40 @@ func (st *State) removeMatchingIPAddressesDocOps(findQuery bson.D) ([]txn.Op, er
 		return nil, errors.Trace(err)
 	}
 
+	var ops []txn.Op
+	var subnetCIDRs []string
+	knownSubnets := make(map[string]*Subnet)
+	subnetRefs := make(map[string]int)
+	for _, doc := range docs {
+		subnet, checked := knownSubnets[doc.SubnetCIDR]
+		if !checked {
+			var err error
+			subnet, err = st.Subnet(doc.SubnetCIDR)
+			if err == nil {
+				subnetCIDRs = append(subnetCIDRs, doc.SubnetCIDR)
+			} else if !errors.IsNotFound(err) {
+				return nil, errors.Trace(err)
+			}
+			knownSubnets[doc.SubnetCIDR] = subnet
+		}
+		known := subnet != nil
+
+		removeOp := removeIPAddressDocOp(doc.DocID)
+		if known {
+			removeOp.Assert = txn.DocExists
+			subnetRefs[doc.SubnetCIDR]++
+		}
+		ops = append(ops, removeOp)
+		if doc.ProviderID != "" {
+			addrID := network.Id(doc.ProviderID)
+			op := st.networkEntityGlobalKeyRemoveOp("address", addrID)
+			ops = append(ops, op)
+		}
+	}
+	for _, cidr := range subnetCIDRs {
+		ops = append(ops, releaseSubnetRefsOp(knownSubnets[cidr], subnetRefs[cidr]))
+	}
+
 	return ops, nil
 }
 
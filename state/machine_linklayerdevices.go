@@ -743,9 +743,11 @@ func (m *Machine) setDevicesAddressesFromDocsOps(newDocs []ipAddressDoc) ([]txn.
 		ops = append(ops, assertLinkLayerDeviceExistsOp(deviceDocID))
 
 		var existingDoc ipAddressDoc
+		var subnetRefDelta int
 		err := addresses.FindId(newDoc.DocID).One(&existingDoc)
 		if err == mgo.ErrNotFound {
 			// Address does not exist yet - insert it.
+			subnetRefDelta = 1
 			ops = append(ops, insertIPAddressDocOp(&newDoc))
 			if newDoc.ProviderID != "" {
 				id := network.Id(newDoc.ProviderID)
@@ -754,6 +756,13 @@ func (m *Machine) setDevicesAddressesFromDocsOps(newDocs []ipAddressDoc) ([]txn.
 		} else if err == nil {
 			// Address already exists - update what's possible.
 			ops = append(ops, updateIPAddressDocOp(&existingDoc, &newDoc))
+			if existingDoc.SubnetCIDR != newDoc.SubnetCIDR {
+				subnetRefDelta = 1
+				ops, err = m.maybeReleaseSubnetRefOps(existingDoc.SubnetCIDR, ops)
+				if err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
 			if newDoc.ProviderID != "" {
 				if existingDoc.ProviderID != "" && existingDoc.ProviderID != newDoc.ProviderID {
 					return nil, errors.Errorf("cannot change ProviderID of link address %q", existingDoc.Value)
@@ -768,7 +777,7 @@ func (m *Machine) setDevicesAddressesFromDocsOps(newDocs []ipAddressDoc) ([]txn.
 			return nil, errors.Trace(err)
 		}
 
-		ops, err = m.maybeAssertSubnetAliveOps(&newDoc, ops)
+		ops, err = m.maybeAssertSubnetAliveOps(&newDoc, subnetRefDelta, ops)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
@@ -776,7 +785,24 @@ func (m *Machine) setDevicesAddressesFromDocsOps(newDocs []ipAddressDoc) ([]txn.
 	return ops, nil
 }
 
-func (m *Machine) maybeAssertSubnetAliveOps(newDoc *ipAddressDoc, opsSoFar []txn.Op) ([]txn.Op, error) {
+// maybeReleaseSubnetRefOps appends an operation decrementing the reference
+// count of the subnet with the given CIDR to opsSoFar, unless the subnet is
+// unknown.
+func (m *Machine) maybeReleaseSubnetRefOps(cidr string, opsSoFar []txn.Op) ([]txn.Op, error) {
+	subnet, err := m.st.Subnet(cidr)
+	if errors.IsNotFound(err) {
+		return opsSoFar, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return append(opsSoFar, releaseSubnetRefsOp(subnet, 1)), nil
+}
+
+// maybeAssertSubnetAliveOps appends an operation asserting the subnet of
+// newDoc is alive to opsSoFar, unless the subnet is unknown. When
+// subnetRefDelta is not zero, the operation also adjusts the subnet's
+// reference count by that amount.
+func (m *Machine) maybeAssertSubnetAliveOps(newDoc *ipAddressDoc, subnetRefDelta int, opsSoFar []txn.Op) ([]txn.Op, error) {
 	subnet, err := m.st.Subnet(newDoc.SubnetCIDR)
 	if errors.IsNotFound(err) {
 		// Subnet is machine-local, no need to assert whether it's alive.
@@ -789,6 +815,9 @@ func (m *Machine) maybeAssertSubnetAliveOps(newDoc *ipAddressDoc, opsSoFar []txn
 	}
 
 	// Subnet exists and is still alive, assert that is stays that way.
+	if subnetRefDelta != 0 {
+		return append(opsSoFar, adjustSubnetRefCountOp(m.st, newDoc.SubnetCIDR, subnetRefDelta)), nil
+	}
 	return append(opsSoFar, txn.Op{
 		C:      subnetsC,
 		Id:     m.st.docID(newDoc.SubnetCIDR),
@@ -800,12 +829,17 @@ func (m *Machine) maybeAssertSubnetAliveOps(newDoc *ipAddressDoc, opsSoFar []txn
 // machine, in a single transaction. No error is returned when some or all of
 // the addresses were already removed.
 func (m *Machine) RemoveAllAddresses() error {
-	ops, err := m.removeAllAddressesOps()
-	if err != nil {
-		return errors.Trace(err)
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		ops, err := m.removeAllAddressesOps()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(ops) == 0 {
+			return nil, jujutxn.ErrNoOperations
+		}
+		return ops, nil
 	}
-
-	return m.st.runTransaction(ops)
+	return m.st.run(buildTxn)
 }
 
 func (m *Machine) removeAllAddressesOps() ([]txn.Op, error) {
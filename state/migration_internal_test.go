@@ -143,6 +143,8 @@ func (s *MigrationSuite) TestKnownCollections(c *gc.C) {
 		providerIDsC,
 		linkLayerDevicesC,
 		linkLayerDevicesRefsC,
+		// The subnet ref-count should not be exported; import it by
+		// adding subnets after IP addresses so AddSubnet recounts them.
 		subnetsC,
 		spacesC,
 
@@ -37,6 +37,10 @@ type Resources interface {
 	// UpdatePendingResource adds the resource to blob storage and updates the metadata.
 	UpdatePendingResource(serviceID, pendingID, userID string, res charmresource.Resource, r io.Reader) (resource.Resource, error)
 
+	// RemovePendingResource removes the identified pending resource
+	// and its data. It is not an error if it does not exist.
+	RemovePendingResource(serviceID, name, pendingID string) error
+
 	// OpenResource returns the metadata for a resource and a reader for the resource.
 	OpenResource(serviceID, name string) (resource.Resource, io.ReadCloser, error)
 
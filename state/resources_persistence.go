@@ -369,6 +369,35 @@ func (p ResourcePersistence) NewResolvePendingResourceOps(resID, pendingID strin
 	return ops, nil
 }
 
+// RemovePendingResource removes the identified pending resource from
+// state, queueing the removal of its blob, if it has one. It is not an
+// error if the pending resource does not exist.
+func (p ResourcePersistence) RemovePendingResource(resID, pendingID string) error {
+	if pendingID == "" {
+		return errors.New("missing pending ID")
+	}
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		doc, err := p.getOnePending(resID, pendingID)
+		if errors.IsNotFound(err) {
+			return nil, jujutxn.ErrNoOperations
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		ops := newRemoveResourcesOps([]resourceDoc{doc})
+		if doc.StoragePath != "" {
+			ops = append(ops, p.base.NewCleanupOp(CleanupKindResourceBlob, doc.StoragePath))
+		}
+		return ops, nil
+	}
+	if err := p.base.Run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
+
 // NewRemoveUnitResourcesOps returns mgo transaction operations
 // that remove resource information specific to the unit from state.
 func (p ResourcePersistence) NewRemoveUnitResourcesOps(unitID string) ([]txn.Op, error) {
@@ -565,6 +565,52 @@ func (s *ResourcePersistenceSuite) TestNewResourcePendingResourceOpsNotFound(c *
 	})
 }
 
+func (s *ResourcePersistenceSuite) TestRemovePendingResourceOkay(c *gc.C) {
+	pendingID := "some-unique-ID-001"
+	stored, doc := newPersistenceResource(c, "a-service", "spam")
+	doc.DocID = pendingResourceID(stored.ID, pendingID)
+	doc.PendingID = pendingID
+	s.base.ReturnOne = doc
+	s.base.ReturnNewCleanupOp = &txn.Op{
+		C:      "cleanups",
+		Insert: "<cleanup>",
+	}
+	p := NewResourcePersistence(s.base)
+	ignoredErr := errors.New("<never reached>")
+	s.stub.SetErrors(nil, nil, nil, nil, ignoredErr)
+
+	err := p.RemovePendingResource(stored.ID, pendingID)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c,
+		"Run",
+		"One",
+		"NewCleanupOp",
+		"RunTransaction",
+	)
+	s.stub.CheckCall(c, 1, "One", "resources", "resource#a-service/spam#pending-some-unique-ID-001", &doc)
+	s.stub.CheckCall(c, 2, "NewCleanupOp", CleanupKindResourceBlob, doc.StoragePath)
+	s.stub.CheckCall(c, 3, "RunTransaction", []txn.Op{{
+		C:      "resources",
+		Id:     doc.DocID,
+		Remove: true,
+	}, {
+		C:      "cleanups",
+		Insert: "<cleanup>",
+	}})
+}
+
+func (s *ResourcePersistenceSuite) TestRemovePendingResourceNotFound(c *gc.C) {
+	p := NewResourcePersistence(s.base)
+	notFound := errors.NewNotFound(nil, "")
+	s.stub.SetErrors(nil, notFound)
+
+	err := p.RemovePendingResource("a-service/spam", "some-unique-ID-001")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.stub.CheckCallNames(c, "Run", "One")
+}
+
 func newPersistenceUnitResources(c *gc.C, serviceID, unitID string, resources []resource.Resource) ([]resource.Resource, []resourceDoc) {
 	var unitResources []resource.Resource
 	var docs []resourceDoc
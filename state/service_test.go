@@ -142,9 +142,9 @@ func (s *ServiceSuite) TestSetCharmPreconditions(c *gc.C) {
 }
 
 func (s *ServiceSuite) TestSetCharmUpdatesBindings(c *gc.C) {
-	_, err := s.State.AddSpace("db", "", nil, false)
+	_, err := s.State.AddSpace("db", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("client", "", nil, true)
+	_, err = s.State.AddSpace("client", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
 	oldCharm := s.AddMetaCharm(c, "mysql", metaBase, 44)
 
@@ -180,9 +180,9 @@ func (s *ServiceSuite) TestSetCharmWithWeirdlyNamedEndpoints(c *gc.C) {
 	// This test ensures if special characters appear in endpoint names of the
 	// charm metadata, they are properly escaped before saving to mongo, and
 	// unescaped when read back.
-	_, err := s.State.AddSpace("client", "", nil, true)
+	_, err := s.State.AddSpace("client", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("db", "", nil, false)
+	_, err = s.State.AddSpace("db", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	initialBindings := map[string]string{
@@ -812,9 +812,9 @@ func (s *ServiceSuite) TestSetCharmRetriesWhenOldBindingsChanged(c *gc.C) {
 		"kludge":  "",
 		"cluster": "",
 	})
-	_, err = s.State.AddSpace("db", "", nil, true)
+	_, err = s.State.AddSpace("db", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("admin", "", nil, false)
+	_, err = s.State.AddSpace("admin", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	updateBindings := func(updatesMap bson.M) {
@@ -2398,9 +2398,9 @@ func (s *ServiceSuite) TestEndpointBindingsJustDefaults(c *gc.C) {
 }
 
 func (s *ServiceSuite) TestEndpointBindingsWithExplictOverrides(c *gc.C) {
-	_, err := s.State.AddSpace("db", "", nil, true)
+	_, err := s.State.AddSpace("db", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("ha", "", nil, false)
+	_, err = s.State.AddSpace("ha", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	bindings := map[string]string{
@@ -2422,7 +2422,7 @@ func (s *ServiceSuite) TestEndpointBindingsWithExplictOverrides(c *gc.C) {
 }
 
 func (s *ServiceSuite) TestSetCharmExtraBindingsUseDefaults(c *gc.C) {
-	_, err := s.State.AddSpace("db", "", nil, true)
+	_, err := s.State.AddSpace("db", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	oldCharm := s.AddMetaCharm(c, "mysql", metaDifferentProvider, 42)
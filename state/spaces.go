@@ -4,8 +4,18 @@
 package state
 
 import (
+	"bytes"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/juju/names"
+	jujutxn "github.com/juju/txn"
+	"github.com/juju/utils/set"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
@@ -26,6 +36,14 @@ type spaceDoc struct {
 	Name       string `bson:"name"`
 	IsPublic   bool   `bson:"is-public"`
 	ProviderId string `bson:"providerid,omitempty"`
+
+	// Created is when the space was added. It is the zero time for
+	// spaces added before it was recorded.
+	Created time.Time `bson:"created,omitempty"`
+
+	// Labels holds organizational metadata about the space, such as
+	// the team or environment it belongs to.
+	Labels map[string]string `bson:"labels,omitempty"`
 }
 
 // Life returns whether the space is Alive, Dying or Dead.
@@ -48,13 +66,79 @@ func (s *Space) Name() string {
 	return s.doc.Name
 }
 
+// Created returns the time the space was added, in UTC. The zero time
+// is returned if it was not recorded.
+func (s *Space) Created() time.Time {
+	return s.doc.Created.UTC()
+}
+
+// Labels returns a copy of the labels of the space. An empty map is
+// returned if it has none.
+func (s *Space) Labels() map[string]string {
+	labels := make(map[string]string, len(s.doc.Labels))
+	for key, value := range s.doc.Labels {
+		labels[key] = value
+	}
+	return labels
+}
+
+// SetLabels replaces the labels of the space, which must be Alive.
+func (s *Space) SetLabels(labels map[string]string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot set labels of space %q", s)
+
+	if err := validateSpaceLabels(labels); err != nil {
+		return errors.Trace(err)
+	}
+	update := bson.D{{"$set", bson.D{{"labels", labels}}}}
+	if len(labels) == 0 {
+		update = bson.D{{"$unset", bson.D{{"labels", 1}}}}
+	}
+	ops := []txn.Op{{
+		C:      spacesC,
+		Id:     s.doc.DocID,
+		Update: update,
+		Assert: isAliveDoc,
+	}}
+
+	txnErr := s.st.runTransaction(ops)
+	if txnErr == nil {
+		s.doc.Labels = copySpaceLabels(labels)
+		return nil
+	}
+	return onAbort(txnErr, errNotAlive)
+}
+
+// validateSpaceLabels returns an error if any of the label keys cannot
+// be stored.
+func validateSpaceLabels(labels map[string]string) error {
+	for key := range labels {
+		if key == "" || strings.HasPrefix(key, "$") || strings.Contains(key, ".") {
+			return errors.NotValidf("label key %q", key)
+		}
+	}
+	return nil
+}
+
+// copySpaceLabels returns a copy of labels, or nil if it is empty.
+func copySpaceLabels(labels map[string]string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(labels))
+	for key, value := range labels {
+		result[key] = value
+	}
+	return result
+}
+
 // ProviderId returns the provider id of the space. This will be the empty
 // string except on substrates that directly support spaces.
 func (s *Space) ProviderId() network.Id {
 	return network.Id(s.doc.ProviderId)
 }
 
-// Subnets returns all the subnets associated with the Space.
+// Subnets returns all the subnets associated with the Space, ordered by
+// network address and then prefix length.
 func (s *Space) Subnets() (results []*Subnet, err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot fetch subnets")
 	name := s.Name()
@@ -72,26 +156,350 @@ func (s *Space) Subnets() (results []*Subnet, err error) {
 	if err := iter.Err(); err != nil {
 		return nil, err
 	}
+	sort.Sort(subnetsByCIDR(results))
+	return results, nil
+}
+
+// subnetsByCIDR sorts subnets by network address and then prefix
+// length, so that 9.0.0.0/8 comes before 10.0.0.0/8. Any CIDRs which
+// cannot be parsed are sorted as strings, after the rest.
+type subnetsByCIDR []*Subnet
+
+func (s subnetsByCIDR) Len() int      { return len(s) }
+func (s subnetsByCIDR) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s subnetsByCIDR) Less(i, j int) bool {
+	_, netI, errI := net.ParseCIDR(s[i].CIDR())
+	_, netJ, errJ := net.ParseCIDR(s[j].CIDR())
+	switch {
+	case errI != nil && errJ != nil:
+		return s[i].CIDR() < s[j].CIDR()
+	case errI != nil || errJ != nil:
+		return errJ != nil
+	}
+	if cmp := bytes.Compare(netI.IP.To16(), netJ.IP.To16()); cmp != 0 {
+		return cmp < 0
+	}
+	onesI, _ := netI.Mask.Size()
+	onesJ, _ := netJ.Mask.Size()
+	return onesI < onesJ
+}
+
+// SubnetCount returns the number of subnets associated with the Space,
+// without loading them.
+func (s *Space) SubnetCount() (int, error) {
+	subnetsCollection, closer := s.st.getCollection(subnetsC)
+	defer closer()
+
+	count, err := subnetsCollection.Find(bson.D{{"space-name", s.Name()}}).Count()
+	if err != nil {
+		return 0, errors.Annotate(err, "cannot count subnets")
+	}
+	return count, nil
+}
+
+// SubnetsInZone returns the subnets associated with the Space which are
+// in the given availability zone. An empty slice is returned if there
+// are no such subnets.
+func (s *Space) SubnetsInZone(zone string) (results []*Subnet, err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot fetch subnets in zone %q", zone)
+	name := s.Name()
+
+	subnetsCollection, closer := s.st.getCollection(subnetsC)
+	defer closer()
+
+	var doc subnetDoc
+	results = []*Subnet{}
+	iter := subnetsCollection.Find(bson.D{
+		{"space-name", name},
+		{"availabilityzone", zone},
+	}).Iter()
+	defer iter.Close()
+	for iter.Next(&doc) {
+		subnet := &Subnet{s.st, doc}
+		results = append(results, subnet)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
 	return results, nil
 }
 
-// AddSpace creates and returns a new space.
-func (st *State) AddSpace(name string, providerId network.Id, subnets []string, isPublic bool) (newSpace *Space, err error) {
+// Zones returns the sorted, distinct availability zones of the subnets
+// associated with the Space. Subnets without a zone are ignored.
+func (s *Space) Zones() ([]string, error) {
+	subnets, err := s.Subnets()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	zones := set.NewStrings()
+	for _, subnet := range subnets {
+		if zone := subnet.AvailabilityZone(); zone != "" {
+			zones.Add(zone)
+		}
+	}
+	return zones.SortedValues(), nil
+}
+
+// InUse returns whether the space is in use: that is, whether any of its
+// subnets holds a machine address, or any constraints name the space,
+// either to require it or to exclude it.
+func (s *Space) InUse() (bool, error) {
+	subnets, err := s.Subnets()
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, subnet := range subnets {
+		if subnet.doc.RefCount > 0 {
+			return true, nil
+		}
+	}
+
+	constraintsCollection, closer := s.st.getCollection(constraintsC)
+	defer closer()
+
+	count, err := constraintsCollection.Find(bson.D{{
+		"spaces", bson.D{{"$in", []string{s.Name(), "^" + s.Name()}}},
+	}}).Count()
+	if err != nil {
+		return false, errors.Annotatef(err, "cannot check constraints for space %q", s)
+	}
+	return count > 0, nil
+}
+
+// SpaceSpec holds the arguments for creating a space with AddSpaces.
+type SpaceSpec struct {
+	// Name is the name of the space.
+	Name string
+
+	// ProviderId is the provider id of the space. This may be empty.
+	ProviderId network.Id
+
+	// Subnets holds the CIDRs of the existing subnets to associate with
+	// the space.
+	Subnets []string
+
+	// IsPublic is whether the space is public.
+	IsPublic bool
+
+	// Labels holds organizational metadata about the space. This may
+	// be empty.
+	Labels map[string]string
+}
+
+// AddSpace creates and returns a new space, with the given labels,
+// which may be nil.
+func (st *State) AddSpace(name string, providerId network.Id, subnets []string, isPublic bool, labels map[string]string) (newSpace *Space, err error) {
 	defer errors.DeferredAnnotatef(&err, "adding space %q", name)
-	if !names.IsValidSpace(name) {
-		return nil, errors.NewNotValid(nil, "invalid space name")
+	spec := SpaceSpec{
+		Name:       name,
+		ProviderId: providerId,
+		Subnets:    subnets,
+		IsPublic:   isPublic,
+		Labels:     labels,
+	}
+	newSpace, ops, err := st.addSpaceOps(spec)
+	if err != nil {
+		return nil, errors.Trace(err)
 	}
 
-	spaceID := st.docID(name)
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		if abortErr := st.addSpaceAbortedError(spec, newSpace); abortErr != nil {
+			return nil, abortErr
+		}
+		return nil, errors.Trace(err)
+	} else if err != nil {
+		return nil, err
+	}
+	return newSpace, nil
+}
+
+// EnsureSpace returns the space with the given name, creating it as
+// AddSpace does if it doesn't exist yet; created reports whether it was
+// created. An existing space is returned only if it is Alive and has the
+// given provider id and public flag, otherwise an error is returned. The
+// subnets are only used when the space is created.
+func (st *State) EnsureSpace(name string, providerId network.Id, subnets []string, isPublic bool) (space *Space, created bool, err error) {
+	defer errors.DeferredAnnotatef(&err, "ensuring space %q", name)
+
+	space, err = st.Space(name)
+	if errors.IsNotFound(err) {
+		space, err = st.AddSpace(name, providerId, subnets, isPublic, nil)
+		if err == nil {
+			return space, true, nil
+		}
+		if !errors.IsAlreadyExists(err) {
+			return nil, false, errors.Trace(err)
+		}
+		// The space was added concurrently; check it matches.
+		space, err = st.Space(name)
+	}
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	if space.Life() != Alive {
+		return nil, false, errors.New("space is not alive")
+	}
+	if space.ProviderId() != providerId {
+		return nil, false, errors.Errorf("space has provider id %q, not %q", space.ProviderId(), providerId)
+	}
+	if space.doc.IsPublic != isPublic {
+		return nil, false, errors.Errorf("space has public flag %v, not %v", space.doc.IsPublic, isPublic)
+	}
+	return space, false, nil
+}
+
+// AddSpaceWithSubnets creates and returns a new space, along with any of
+// the given subnets that do not already exist, in a single transaction.
+// Subnets that already exist, identified by CIDR, are reused and moved to
+// the new space; the rest are created in it. The SpaceName of each of the
+// subnets is ignored.
+func (st *State) AddSpaceWithSubnets(name string, providerId network.Id, subnets []SubnetInfo, isPublic bool) (newSpace *Space, err error) {
+	defer errors.DeferredAnnotatef(&err, "adding space %q", name)
+
+	var cidrs, existing []string
+	var newSubnets []*Subnet
+	providerIds := make(map[string]bool)
+	for _, info := range subnets {
+		cidrs = append(cidrs, info.CIDR)
+		if _, err := st.Subnet(info.CIDR); err == nil {
+			existing = append(existing, info.CIDR)
+			continue
+		} else if !errors.IsNotFound(err) {
+			return nil, errors.Trace(err)
+		}
+		info.SpaceName = name
+		subnet, err := st.newSubnet(info)
+		if err != nil {
+			return nil, errors.Annotatef(err, "adding subnet %q", info.CIDR)
+		}
+		if subnet.doc.ProviderId != "" {
+			if providerIds[subnet.doc.ProviderId] {
+				return nil, errors.Errorf("adding subnet %q: ProviderId %q not unique", info.CIDR, subnet.doc.ProviderId)
+			}
+			providerIds[subnet.doc.ProviderId] = true
+		}
+		newSubnets = append(newSubnets, subnet)
+	}
+	if err := checkCIDRsOverlap(cidrs); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	spec := SpaceSpec{
+		Name:       name,
+		ProviderId: providerId,
+		Subnets:    existing,
+		IsPublic:   isPublic,
+	}
+	newSpace, ops, err := st.addSpaceOps(spec)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, subnet := range newSubnets {
+		subnetOps, err := st.addSubnetOps(subnet)
+		if err != nil {
+			return nil, errors.Annotatef(err, "adding subnet %q", subnet.CIDR())
+		}
+		ops = append(ops, subnetOps...)
+	}
+
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		if abortErr := st.addSpaceAbortedError(spec, newSpace); abortErr != nil {
+			return nil, abortErr
+		}
+		for _, subnet := range newSubnets {
+			if abortErr := st.addSubnetAbortedError(subnet); abortErr != nil {
+				return nil, errors.Annotatef(abortErr, "adding subnet %q", subnet.CIDR())
+			}
+		}
+		return nil, errors.Trace(err)
+	} else if err != nil {
+		return nil, err
+	}
+	return newSpace, nil
+}
+
+// AddSpaces creates and returns the spaces described by specs, in a single
+// transaction. Either all of the spaces are created, or none of them are.
+func (st *State) AddSpaces(specs []SpaceSpec) (newSpaces []*Space, err error) {
+	spaceNames := make(map[string]bool)
+	providerIds := make(map[network.Id]bool)
+	subnetSpaces := make(map[string]string)
+	for _, spec := range specs {
+		// Space names must be unique regardless of case, as
+		// checked against existing spaces by addSpaceOps.
+		name := strings.ToLower(spec.Name)
+		if spaceNames[name] {
+			return nil, errors.Errorf("adding space %q: space specified more than once", spec.Name)
+		}
+		spaceNames[name] = true
+		if spec.ProviderId != "" {
+			if providerIds[spec.ProviderId] {
+				return nil, errors.Errorf("adding space %q: ProviderId %q not unique", spec.Name, spec.ProviderId)
+			}
+			providerIds[spec.ProviderId] = true
+		}
+		for _, subnetId := range spec.Subnets {
+			if other, ok := subnetSpaces[subnetId]; ok && other != spec.Name {
+				return nil, errors.Errorf("adding space %q: subnet %q already specified for space %q", spec.Name, subnetId, other)
+			}
+			subnetSpaces[subnetId] = spec.Name
+		}
+	}
+
+	var ops []txn.Op
+	for _, spec := range specs {
+		newSpace, spaceOps, err := st.addSpaceOps(spec)
+		if err != nil {
+			return nil, errors.Annotatef(err, "adding space %q", spec.Name)
+		}
+		newSpaces = append(newSpaces, newSpace)
+		ops = append(ops, spaceOps...)
+	}
+	if len(ops) == 0 {
+		return []*Space{}, nil
+	}
+
+	if err := st.runTransaction(ops); err == txn.ErrAborted {
+		for i, spec := range specs {
+			if abortErr := st.addSpaceAbortedError(spec, newSpaces[i]); abortErr != nil {
+				return nil, errors.Annotatef(abortErr, "adding space %q", spec.Name)
+			}
+		}
+		return nil, errors.Annotate(err, "adding spaces")
+	} else if err != nil {
+		return nil, errors.Annotate(err, "adding spaces")
+	}
+	return newSpaces, nil
+}
+
+// addSpaceOps validates the given spec, and returns the space to be
+// created along with the operations needed to create it.
+func (st *State) addSpaceOps(spec SpaceSpec) (*Space, []txn.Op, error) {
+	if !names.IsValidSpace(spec.Name) {
+		return nil, nil, errors.NewNotValid(nil, "invalid space name")
+	}
+	if err := st.checkSpaceNameCaseUnique(spec.Name, ""); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if err := st.checkSpaceSubnetsOverlap(spec.Name, spec.Subnets); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if err := validateSpaceLabels(spec.Labels); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+
+	spaceID := st.docID(spec.Name)
 	spaceDoc := spaceDoc{
 		DocID:      spaceID,
 		ModelUUID:  st.ModelUUID(),
 		Life:       Alive,
-		Name:       name,
-		IsPublic:   isPublic,
-		ProviderId: string(providerId),
+		Name:       spec.Name,
+		IsPublic:   spec.IsPublic,
+		ProviderId: string(spec.ProviderId),
+		Created:    nowToTheSecond(),
+		Labels:     copySpaceLabels(spec.Labels),
 	}
-	newSpace = &Space{doc: spaceDoc, st: st}
+	newSpace := &Space{doc: spaceDoc, st: st}
 
 	ops := []txn.Op{{
 		C:      spacesC,
@@ -100,42 +508,314 @@ func (st *State) AddSpace(name string, providerId network.Id, subnets []string,
 		Insert: spaceDoc,
 	}}
 
-	if providerId != "" {
-		ops = append(ops, st.networkEntityGlobalKeyOp("space", providerId))
+	if spec.ProviderId != "" {
+		ops = append(ops, st.networkEntityGlobalKeyOp("space", spec.ProviderId))
 	}
 
-	for _, subnetId := range subnets {
-		// TODO:(mfoord) once we have refcounting for subnets we should
-		// also assert that the refcount is zero as moving the space of a
-		// subnet in use is not permitted.
+	for _, subnetId := range spec.Subnets {
+		// Moving the space of a subnet in use is not permitted.
 		ops = append(ops, txn.Op{
 			C:      subnetsC,
 			Id:     st.docID(subnetId),
-			Assert: txn.DocExists,
-			Update: bson.D{{"$set", bson.D{{"space-name", name}}}},
+			Assert: subnetHasNoRefsAssert,
+			Update: bson.D{{"$set", bson.D{{"space-name", spec.Name}}}},
 		})
 	}
+	return newSpace, ops, nil
+}
 
-	if err := st.runTransaction(ops); err == txn.ErrAborted {
-		if _, err := st.Space(name); err == nil {
-			return nil, errors.AlreadyExistsf("space %q", name)
+// checkSpaceNameCaseUnique returns an error satisfying
+// errors.IsAlreadyExists if there is a space whose name differs from
+// name only in case. A space with exactly the same name is left to be
+// caught by the transaction adding the new space. The space named except,
+// if any, is ignored so that a space can be renamed to a name differing
+// from its own only in case.
+func (st *State) checkSpaceNameCaseUnique(name, except string) error {
+	spaces, closer := st.getCollection(spacesC)
+	defer closer()
+
+	pattern := bson.RegEx{Pattern: "^" + regexp.QuoteMeta(name) + "$", Options: "i"}
+	var docs []spaceDoc
+	if err := spaces.Find(bson.D{{"name", pattern}}).All(&docs); err != nil {
+		return errors.Annotate(err, "cannot check for existing spaces")
+	}
+	for _, doc := range docs {
+		if doc.Name != name && doc.Name != except {
+			return errors.NewAlreadyExists(nil, fmt.Sprintf("space %q already exists as %q", name, doc.Name))
 		}
-		for _, subnetId := range subnets {
-			if _, err := st.Subnet(subnetId); errors.IsNotFound(err) {
-				return nil, err
-			}
+	}
+	return nil
+}
+
+// addSpaceAbortedError returns an error describing why the transaction
+// adding newSpace, described by spec, was aborted. If the reason cannot
+// be determined, nil is returned.
+func (st *State) addSpaceAbortedError(spec SpaceSpec, newSpace *Space) error {
+	if _, err := st.Space(spec.Name); err == nil {
+		return errors.AlreadyExistsf("space %q", spec.Name)
+	}
+	for _, subnetId := range spec.Subnets {
+		subnet, err := st.Subnet(subnetId)
+		if errors.IsNotFound(err) {
+			return err
+		} else if err == nil && subnet.doc.RefCount > 0 {
+			return st.subnetInUseError(subnet.CIDR())
 		}
+	}
+	if spec.ProviderId != "" {
 		if err := newSpace.Refresh(); err != nil {
 			if errors.IsNotFound(err) {
-				return nil, errors.Errorf("ProviderId %q not unique", providerId)
+				return errors.Errorf("ProviderId %q not unique", spec.ProviderId)
 			}
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// RenameSpace renames the Alive space oldName to newName, updating all
+// subnets, constraints and endpoint bindings referring to the space in the
+// same transaction. An error satisfying errors.IsAlreadyExists is returned
+// if a space named newName, ignoring case, already exists. Spaces with
+// subnets in use by machines cannot be renamed.
+func (st *State) RenameSpace(oldName, newName string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "renaming space %q to %q", oldName, newName)
+	if !names.IsValidSpace(newName) {
+		return errors.NewNotValid(nil, "invalid space name")
+	}
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		space, err := st.Space(oldName)
+		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		return nil, errors.Trace(err)
-	} else if err != nil {
-		return nil, err
+		if space.Life() != Alive {
+			return nil, errors.Errorf("space %q is not alive", oldName)
+		}
+		if _, err := st.Space(newName); err == nil {
+			return nil, errors.AlreadyExistsf("space %q", newName)
+		} else if !errors.IsNotFound(err) {
+			return nil, errors.Trace(err)
+		}
+		if err := st.checkSpaceNameCaseUnique(newName, oldName); err != nil {
+			return nil, errors.Trace(err)
+		}
+		subnets, err := space.Subnets()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+
+		newDoc := space.doc
+		newDoc.DocID = st.docID(newName)
+		newDoc.Name = newName
+		ops := []txn.Op{{
+			C:      spacesC,
+			Id:     space.doc.DocID,
+			Assert: isAliveDoc,
+			Remove: true,
+		}, {
+			C:      spacesC,
+			Id:     newDoc.DocID,
+			Assert: txn.DocMissing,
+			Insert: newDoc,
+		}}
+		for _, subnet := range subnets {
+			if subnet.doc.RefCount > 0 {
+				return nil, st.subnetInUseError(subnet.CIDR())
+			}
+			ops = append(ops, txn.Op{
+				C:      subnetsC,
+				Id:     subnet.doc.DocID,
+				Assert: append(bson.D{{"space-name", oldName}}, subnetHasNoRefsAssert...),
+				Update: bson.D{{"$set", bson.D{{"space-name", newName}}}},
+			})
+		}
+		refOps, err := st.renameSpaceReferencesOps(oldName, newName)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, refOps...), nil
 	}
-	return newSpace, nil
+	return st.run(buildTxn)
+}
+
+// renameSpaceReferencesOps returns the operations changing any constraints
+// and endpoint bindings referring to the space oldName to refer to newName
+// instead. Each operation asserts the document is unchanged since it was
+// read.
+func (st *State) renameSpaceReferencesOps(oldName, newName string) ([]txn.Op, error) {
+	constraintsColl, closer := st.getCollection(constraintsC)
+	defer closer()
+
+	var ops []txn.Op
+	var consDoc struct {
+		DocID  string   `bson:"_id"`
+		Spaces []string `bson:"spaces"`
+	}
+	excluded := "^" + oldName
+	query := bson.D{{"spaces", bson.D{{"$in", []string{oldName, excluded}}}}}
+	iter := constraintsColl.Find(query).Iter()
+	for iter.Next(&consDoc) {
+		spaces := make([]string, len(consDoc.Spaces))
+		for i, space := range consDoc.Spaces {
+			switch space {
+			case oldName:
+				space = newName
+			case excluded:
+				space = "^" + newName
+			}
+			spaces[i] = space
+		}
+		ops = append(ops, txn.Op{
+			C:      constraintsC,
+			Id:     consDoc.DocID,
+			Assert: bson.D{{"spaces", consDoc.Spaces}},
+			Update: bson.D{{"$set", bson.D{{"spaces", spaces}}}},
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Annotate(err, "cannot read constraints")
+	}
+
+	bindingsColl, closer := st.getCollection(endpointBindingsC)
+	defer closer()
+
+	var bindingsDoc endpointBindingsDoc
+	sanitize := inSubdocEscapeReplacer("bindings")
+	iter = bindingsColl.Find(nil).Iter()
+	for iter.Next(&bindingsDoc) {
+		changes := make(bson.M)
+		for endpoint, space := range bindingsDoc.Bindings {
+			if space == oldName {
+				changes[sanitize(endpoint)] = newName
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		ops = append(ops, txn.Op{
+			C:      endpointBindingsC,
+			Id:     bindingsDoc.DocID,
+			Assert: bson.D{{"txn-revno", bindingsDoc.TxnRevno}},
+			Update: bson.D{{"$set", changes}},
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Annotate(err, "cannot read endpoint bindings")
+	}
+	return ops, nil
+}
+
+// MoveSubnetsFrom moves the subnets with the given CIDRs from the space
+// other to s, in a single transaction. Both spaces must be Alive, and
+// every subnet must currently belong to other; otherwise no subnets are
+// moved. Subnets in use by machines cannot be moved.
+func (s *Space) MoveSubnetsFrom(other *Space, subnetIds []string) (err error) {
+	defer errors.DeferredAnnotatef(&err, "moving subnets from space %q to %q", other, s)
+	if s.doc.DocID == other.doc.DocID {
+		return errors.New("cannot move subnets to the same space")
+	}
+	if err := s.st.checkSpaceSubnetsOverlap(s.Name(), subnetIds); err != nil {
+		return errors.Trace(err)
+	}
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		ops := []txn.Op{}
+		for _, space := range []*Space{s, other} {
+			if attempt > 0 {
+				if err := space.Refresh(); err != nil {
+					return nil, errors.Trace(err)
+				}
+			}
+			if space.Life() != Alive {
+				return nil, errors.Errorf("space %q is not alive", space)
+			}
+			ops = append(ops, txn.Op{
+				C:      spacesC,
+				Id:     space.doc.DocID,
+				Assert: isAliveDoc,
+			})
+		}
+		// Touch the destination space, so a concurrent EnsureDead
+		// (which asserts its txn-revno) sees the incoming subnets.
+		ops[0].Update = bson.D{{"$set", bson.D{{"life", Alive}}}}
+		for _, subnetId := range subnetIds {
+			subnet, err := s.st.Subnet(subnetId)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if subnet.SpaceName() != other.Name() {
+				return nil, errors.Errorf("subnet %q is not in space %q", subnetId, other)
+			}
+			if subnet.doc.RefCount > 0 {
+				return nil, s.st.subnetInUseError(subnet.CIDR())
+			}
+			ops = append(ops, txn.Op{
+				C:      subnetsC,
+				Id:     subnet.doc.DocID,
+				Assert: append(bson.D{{"space-name", other.Name()}}, subnetHasNoRefsAssert...),
+				Update: bson.D{{"$set", bson.D{{"space-name", s.Name()}}}},
+			})
+		}
+		return ops, nil
+	}
+	return s.st.run(buildTxn)
+}
+
+// checkSpaceSubnetsOverlap returns an error if the CIDRs of any of the
+// given subnets overlap with each other, or with the CIDR of any subnet
+// already associated with the named space.
+func (st *State) checkSpaceSubnetsOverlap(name string, subnetIds []string) error {
+	var cidrs []string
+	adding := make(map[string]bool)
+	for _, subnetId := range subnetIds {
+		if adding[subnetId] {
+			continue
+		}
+		subnet, err := st.Subnet(subnetId)
+		if err != nil {
+			return err
+		}
+		adding[subnetId] = true
+		cidrs = append(cidrs, subnet.CIDR())
+	}
+
+	subnetsCollection, closer := st.getCollection(subnetsC)
+	defer closer()
+
+	var doc subnetDoc
+	iter := subnetsCollection.Find(bson.D{{"space-name", name}}).Iter()
+	for iter.Next(&doc) {
+		if !adding[doc.CIDR] {
+			cidrs = append(cidrs, doc.CIDR)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return errors.Annotate(err, "cannot read subnets")
+	}
+
+	return checkCIDRsOverlap(cidrs)
+}
+
+// checkCIDRsOverlap returns an error if any of the given CIDRs overlap
+// with each other.
+func checkCIDRsOverlap(cidrs []string) error {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return errors.Annotatef(err, "invalid CIDR %q", cidr)
+		}
+		nets[i] = ipNet
+	}
+	for i := range nets {
+		for j := i + 1; j < len(nets); j++ {
+			if nets[i].Contains(nets[j].IP) || nets[j].Contains(nets[i].IP) {
+				return errors.Errorf("subnet %q overlaps with subnet %q", cidrs[i], cidrs[j])
+			}
+		}
+	}
+	return nil
 }
 
 // Space returns a space from state that matches the provided name. An error
@@ -156,6 +836,20 @@ func (st *State) Space(name string) (*Space, error) {
 	return &Space{st, doc}, nil
 }
 
+// SpaceByCIDR returns the space containing the subnet with the given
+// CIDR. An error satisfying errors.IsNotFound is returned if there is
+// no such subnet, or if the subnet is not in any space.
+func (st *State) SpaceByCIDR(cidr string) (*Space, error) {
+	subnet, err := st.Subnet(cidr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if subnet.SpaceName() == "" {
+		return nil, errors.NotFoundf("space for subnet %q", cidr)
+	}
+	return st.Space(subnet.SpaceName())
+}
+
 // AllSpaces returns all spaces for the model.
 func (st *State) AllSpaces() ([]*Space, error) {
 	spacesCollection, closer := st.getCollection(spacesC)
@@ -173,9 +867,134 @@ func (st *State) AllSpaces() ([]*Space, error) {
 	return spaces, nil
 }
 
+// SpaceCount returns the number of spaces in the model.
+func (st *State) SpaceCount() (int, error) {
+	spacesCollection, closer := st.getCollection(spacesC)
+	defer closer()
+
+	count, err := spacesCollection.Count()
+	if err != nil {
+		return 0, errors.Annotatef(err, "cannot count spaces")
+	}
+	return count, nil
+}
+
+// AllSpacesPaged returns at most limit spaces for the model, sorted by
+// name, skipping the first offset of them. An empty slice is returned if
+// offset is beyond the last space.
+func (st *State) AllSpacesPaged(offset, limit int) ([]*Space, error) {
+	if offset < 0 {
+		return nil, errors.NotValidf("negative offset %d", offset)
+	}
+	if limit <= 0 {
+		return nil, errors.NotValidf("non-positive limit %d", limit)
+	}
+	spacesCollection, closer := st.getCollection(spacesC)
+	defer closer()
+
+	docs := []spaceDoc{}
+	err := spacesCollection.Find(nil).Sort("name").Skip(offset).Limit(limit).All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get spaces")
+	}
+	spaces := make([]*Space, len(docs))
+	for i, doc := range docs {
+		spaces[i] = &Space{st: st, doc: doc}
+	}
+	return spaces, nil
+}
+
+// SpaceIterator iterates over spaces in the model, reading them from
+// the database one at a time. It must be closed after use.
+type SpaceIterator struct {
+	st     *State
+	iter   *mgo.Iter
+	closer func()
+}
+
+// SpacesIter returns an iterator over all the spaces in the model, in
+// order of name.
+func (st *State) SpacesIter() (*SpaceIterator, error) {
+	spacesCollection, closer := st.getCollection(spacesC)
+	iter := spacesCollection.Find(nil).Sort("name").Iter()
+	return &SpaceIterator{st: st, iter: iter, closer: closer}, nil
+}
+
+// Next sets space to the next space and returns true, or returns false
+// if there are no more spaces or an error occurred; Err distinguishes
+// between the two.
+func (i *SpaceIterator) Next(space *Space) bool {
+	var doc spaceDoc
+	if !i.iter.Next(&doc) {
+		return false
+	}
+	*space = Space{st: i.st, doc: doc}
+	return true
+}
+
+// Err returns the error, if any, that stopped the iteration.
+func (i *SpaceIterator) Err() error {
+	if err := i.iter.Err(); err != nil {
+		return errors.Annotate(err, "cannot read spaces")
+	}
+	return nil
+}
+
+// Close releases the resources held by the iterator, and returns the
+// error, if any, that stopped the iteration.
+func (i *SpaceIterator) Close() error {
+	defer i.closer()
+	if err := i.iter.Close(); err != nil {
+		return errors.Annotate(err, "cannot read spaces")
+	}
+	return nil
+}
+
+// SpacesByVisibility returns all spaces for the model which are public,
+// or all spaces which are not, as requested. An empty slice is returned
+// if there are no matching spaces.
+func (st *State) SpacesByVisibility(public bool) ([]*Space, error) {
+	spaces, err := st.findSpaces(bson.D{{"is-public", public}})
+	return spaces, errors.Annotate(err, "cannot get spaces by visibility")
+}
+
+// ProviderSpaces returns all spaces for the model which correspond to a
+// construct in the underlying substrate; that is, spaces with a provider
+// id. An empty slice is returned if there are none.
+func (st *State) ProviderSpaces() ([]*Space, error) {
+	spaces, err := st.findSpaces(bson.D{{"providerid", bson.D{{"$nin", []interface{}{nil, ""}}}}})
+	return spaces, errors.Annotate(err, "cannot get provider spaces")
+}
+
+// ManualSpaces returns all spaces for the model which have no provider
+// id, having been created by an operator rather than discovered from
+// the substrate. An empty slice is returned if there are none.
+func (st *State) ManualSpaces() ([]*Space, error) {
+	spaces, err := st.findSpaces(bson.D{{"providerid", bson.D{{"$in", []interface{}{nil, ""}}}}})
+	return spaces, errors.Annotate(err, "cannot get manual spaces")
+}
+
+// findSpaces returns the spaces matching query.
+func (st *State) findSpaces(query bson.D) ([]*Space, error) {
+	spacesCollection, closer := st.getCollection(spacesC)
+	defer closer()
+
+	docs := []spaceDoc{}
+	if err := spacesCollection.Find(query).All(&docs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	spaces := make([]*Space, len(docs))
+	for i, doc := range docs {
+		spaces[i] = &Space{st: st, doc: doc}
+	}
+	return spaces, nil
+}
+
 // EnsureDead sets the Life of the space to Dead, if it's Alive. If the space is
 // already Dead, no error is returned. When the space is no longer Alive or
-// already removed, errNotAlive is returned.
+// already removed, errNotAlive is returned. An error listing the subnets still
+// associated with the space is returned if there are any, as they must be
+// moved to another space first.
 func (s *Space) EnsureDead() (err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot set space %q to dead", s)
 
@@ -183,23 +1002,69 @@ func (s *Space) EnsureDead() (err error) {
 		return nil
 	}
 
-	ops := []txn.Op{{
-		C:      spacesC,
-		Id:     s.doc.DocID,
-		Update: bson.D{{"$set", bson.D{{"life", Dead}}}},
-		Assert: isAliveDoc,
-	}}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); errors.IsNotFound(err) {
+				return nil, errNotAlive
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if s.doc.Life == Dead {
+				return nil, jujutxn.ErrNoOperations
+			}
+		}
+		// Subnets are moved into a space in transactions which touch the
+		// space document, so asserting its txn-revno below guarantees no
+		// subnet was added after this check.
+		txnRevno, err := s.txnRevno()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		subnets, err := s.Subnets()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(subnets) > 0 {
+			cidrs := make([]string, len(subnets))
+			for i, subnet := range subnets {
+				cidrs[i] = subnet.CIDR()
+			}
+			return nil, errors.Errorf("space still has subnets: %s", strings.Join(cidrs, ", "))
+		}
+		return []txn.Op{{
+			C:      spacesC,
+			Id:     s.doc.DocID,
+			Update: bson.D{{"$set", bson.D{{"life", Dead}}}},
+			Assert: append(isAliveDoc, bson.DocElem{"txn-revno", txnRevno}),
+		}}, nil
+	}
+	if err := s.st.run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+	s.doc.Life = Dead
+	return nil
+}
 
-	txnErr := s.st.runTransaction(ops)
-	if txnErr == nil {
-		s.doc.Life = Dead
-		return nil
+// txnRevno returns the current txn-revno of the space document.
+func (s *Space) txnRevno() (int64, error) {
+	spaces, closer := s.st.getCollection(spacesC)
+	defer closer()
+
+	var doc struct {
+		TxnRevno int64 `bson:"txn-revno"`
 	}
-	return onAbort(txnErr, errNotAlive)
+	err := spaces.FindId(s.doc.DocID).Select(bson.D{{"txn-revno", 1}}).One(&doc)
+	if err == mgo.ErrNotFound {
+		return 0, errNotAlive
+	} else if err != nil {
+		return 0, errors.Annotate(err, "cannot read space")
+	}
+	return doc.TxnRevno, nil
 }
 
 // Remove removes a Dead space. If the space is not Dead or it is already
-// removed, an error is returned.
+// removed, an error is returned. Any subnets still referring to the space
+// are returned to the unassigned pool in the same transaction.
 func (s *Space) Remove() (err error) {
 	defer errors.DeferredAnnotatef(&err, "cannot remove space %q", s)
 
@@ -207,21 +1072,95 @@ func (s *Space) Remove() (err error) {
 		return errors.New("space is not dead")
 	}
 
-	ops := []txn.Op{{
-		C:      spacesC,
-		Id:     s.doc.DocID,
-		Remove: true,
-		Assert: isDeadDoc,
-	}}
-	if s.ProviderId() != "" {
-		ops = append(ops, s.st.networkEntityGlobalKeyRemoveOp("space", s.ProviderId()))
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		if attempt > 0 {
+			if err := s.Refresh(); errors.IsNotFound(err) {
+				return nil, errors.New("not found or not dead")
+			} else if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		ops := []txn.Op{{
+			C:      spacesC,
+			Id:     s.doc.DocID,
+			Remove: true,
+			Assert: isDeadDoc,
+		}}
+		if s.ProviderId() != "" {
+			ops = append(ops, s.st.networkEntityGlobalKeyRemoveOp("space", s.ProviderId()))
+		}
+		subnetOps, err := s.unassignSubnetsOps()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, subnetOps...), nil
 	}
+	return s.st.run(buildTxn)
+}
 
-	txnErr := s.st.runTransaction(ops)
-	if txnErr == nil {
+// unassignSubnetsOps returns the operations needed to clear the space
+// name of every subnet still referring to s.
+func (s *Space) unassignSubnetsOps() ([]txn.Op, error) {
+	subnetsCollection, closer := s.st.getCollection(subnetsC)
+	defer closer()
+
+	var ops []txn.Op
+	var doc subnetDoc
+	iter := subnetsCollection.Find(bson.D{{"space-name", s.Name()}}).Iter()
+	for iter.Next(&doc) {
+		ops = append(ops, txn.Op{
+			C:      subnetsC,
+			Id:     doc.DocID,
+			Assert: bson.D{{"space-name", s.Name()}},
+			Update: bson.D{{"$unset", bson.D{{"space-name", 1}}}},
+		})
+	}
+	if err := iter.Close(); err != nil {
+		return nil, errors.Annotate(err, "cannot read subnets")
+	}
+	return ops, nil
+}
+
+// ForceRemove removes a Dead space on a best-effort basis, for use when
+// Remove fails because the space was left in an inconsistent state. Any
+// subnets still referring to the space are returned to the unassigned
+// pool along with the space document. The global key reserving its
+// provider id is removed independently: no error is returned if either
+// is already gone.
+func (s *Space) ForceRemove() (err error) {
+	defer errors.DeferredAnnotatef(&err, "cannot force remove space %q", s)
+
+	if s.doc.Life != Dead {
+		return errors.New("space is not dead")
+	}
+
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		ops := []txn.Op{{
+			C:      spacesC,
+			Id:     s.doc.DocID,
+			Remove: true,
+		}}
+		subnetOps, err := s.unassignSubnetsOps()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, subnetOps...), nil
+	}
+	if err := s.st.run(buildTxn); err != nil {
+		return errors.Trace(err)
+	}
+
+	if s.ProviderId() == "" {
+		return nil
+	}
+	op := s.st.networkEntityGlobalKeyRemoveOp("space", s.ProviderId())
+	op.Assert = txn.DocExists
+	err = s.st.runTransaction([]txn.Op{op})
+	if err == txn.ErrAborted {
+		logger.Warningf("provider id %q of space %q already removed", s.ProviderId(), s)
 		return nil
 	}
-	return onAbort(txnErr, errors.New("not found or not dead"))
+	return errors.Trace(err)
 }
 
 // Refresh: refreshes the contents of the Space from the underlying state. It
@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
 
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/state"
 )
@@ -86,7 +89,7 @@ func (s *SpacesSuite) addSpaceWithSubnets(c *gc.C, args addSpaceArgs) (*state.Sp
 		args.ForState = s.State
 	}
 	s.addSubnetsForState(c, args.SubnetCIDRs, args.ForState)
-	return args.ForState.AddSpace(args.Name, args.ProviderId, args.SubnetCIDRs, args.IsPublic)
+	return args.ForState.AddSpace(args.Name, args.ProviderId, args.SubnetCIDRs, args.IsPublic, nil)
 }
 
 func (s *SpacesSuite) assertSpaceNotFound(c *gc.C, name string) {
@@ -410,11 +413,49 @@ func (s *SpacesSuite) TestAddSpaceWhenSubnetNotFound(c *gc.C) {
 	subnets := []string{"1.1.1.0/24"}
 	isPublic := false
 
-	_, err := s.State.AddSpace(name, "", subnets, isPublic)
+	_, err := s.State.AddSpace(name, "", subnets, isPublic, nil)
 	c.Assert(err, gc.ErrorMatches, `adding space "my-space": subnet "1.1.1.0/24" not found`)
 	s.assertSpaceNotFound(c, name)
 }
 
+func (s *SpacesSuite) TestAddSpaceWithOverlappingSubnetsFails(c *gc.C) {
+	args := addSpaceArgs{
+		Name:        "my-space",
+		SubnetCIDRs: []string{"10.0.0.0/16", "10.0.1.0/24"},
+	}
+	_, err := s.addSpaceWithSubnets(c, args)
+	c.Assert(err, gc.ErrorMatches,
+		`adding space "my-space": subnet "10.0.0.0/16" overlaps with subnet "10.0.1.0/24"`,
+	)
+	s.assertSpaceNotFound(c, args.Name)
+
+	subnet, err := s.State.Subnet("10.0.1.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnet.SpaceName(), gc.Equals, "")
+}
+
+func (s *SpacesSuite) TestAddSpaceWithNameDifferingOnlyInCaseFails(c *gc.C) {
+	// Space names are validated as lower case, but spaces added
+	// before that was enforced may not be.
+	spaces, closer := state.GetCollection(s.State, "spaces")
+	defer closer()
+	err := spaces.Writeable().Insert(bson.D{
+		{"_id", "Public"},
+		{"name", "Public"},
+		{"life", state.Alive},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.AddSpace("public", "", nil, true, nil)
+	c.Assert(err, gc.ErrorMatches, `adding space "public": space "public" already exists as "Public"`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	s.assertSpaceNotFound(c, "public")
+
+	_, err = s.State.AddSpaces([]state.SpaceSpec{{Name: "private"}, {Name: "public"}})
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	s.assertSpaceNotFound(c, "private")
+}
+
 func (s *SpacesSuite) TestAddSpaceWithNonEmptyProviderIdAndInvalidNameFails(c *gc.C) {
 	args := addSpaceArgs{
 		Name:       "-bad name-",
@@ -476,6 +517,408 @@ func (s *SpacesSuite) TestSubnetsReturnsExpectedSubnets(c *gc.C) {
 	c.Assert(actual, jc.DeepEquals, expected)
 }
 
+func (s *SpacesSuite) TestSubnetsSortedByCIDR(c *gc.C) {
+	args := addSpaceArgs{
+		Name:        "my-space",
+		SubnetCIDRs: []string{"3.1.1.0/24", "1.1.1.0/24", "2.1.1.0/24"},
+	}
+	space, err := s.addSpaceWithSubnets(c, args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	subnets, err := space.Subnets()
+	c.Assert(err, jc.ErrorIsNil)
+	cidrs := make([]string, len(subnets))
+	for i, subnet := range subnets {
+		cidrs[i] = subnet.CIDR()
+	}
+	c.Assert(cidrs, jc.DeepEquals, []string{"1.1.1.0/24", "2.1.1.0/24", "3.1.1.0/24"})
+}
+
+func (s *SpacesSuite) TestSubnetsSortedByNetworkAddress(c *gc.C) {
+	args := addSpaceArgs{
+		Name:        "my-space",
+		SubnetCIDRs: []string{"10.0.0.0/24", "9.0.0.0/24", "100.0.0.0/8", "10.10.0.0/16", "9.1.0.0/16"},
+	}
+	space, err := s.addSpaceWithSubnets(c, args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	subnets, err := space.Subnets()
+	c.Assert(err, jc.ErrorIsNil)
+	cidrs := make([]string, len(subnets))
+	for i, subnet := range subnets {
+		cidrs[i] = subnet.CIDR()
+	}
+	c.Assert(cidrs, jc.DeepEquals, []string{
+		"9.0.0.0/24", "9.1.0.0/16", "10.0.0.0/24", "10.10.0.0/16", "100.0.0.0/8",
+	})
+}
+
+func (s *SpacesSuite) TestSubnetCount(c *gc.C) {
+	args := addSpaceArgs{
+		Name:        "my-space",
+		SubnetCIDRs: []string{"1.1.1.0/24", "2.1.1.0/24"},
+	}
+	space, err := s.addSpaceWithSubnets(c, args)
+	c.Assert(err, jc.ErrorIsNil)
+	// Subnets in other spaces are not counted.
+	s.addSubnets(c, []string{"3.1.1.0/24"})
+	_, err = s.State.AddSpace("other-space", "", []string{"3.1.1.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	count, err := space.SubnetCount()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 2)
+
+	empty := s.addAliveSpace(c, "empty")
+	count, err = empty.SubnetCount()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 0)
+}
+
+func (s *SpacesSuite) TestSubnetsInZone(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24"})
+	_, err := s.State.AddSubnet(state.SubnetInfo{
+		CIDR:             "2.1.1.0/24",
+		AvailabilityZone: "zone2",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	space, err := s.State.AddSpace("my-space", "", []string{"1.1.1.0/24", "2.1.1.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	expected, err := s.State.Subnet("2.1.1.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	actual, err := space.SubnetsInZone("zone2")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actual, jc.DeepEquals, []*state.Subnet{expected})
+
+	actual, err = space.SubnetsInZone("zone3")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(actual, gc.HasLen, 0)
+	c.Assert(actual, gc.NotNil)
+}
+
+func (s *SpacesSuite) TestZones(c *gc.C) {
+	for _, info := range []state.SubnetInfo{
+		{CIDR: "1.1.1.0/24", AvailabilityZone: "zone2"},
+		{CIDR: "2.1.1.0/24", AvailabilityZone: "zone1"},
+		{CIDR: "3.1.1.0/24", AvailabilityZone: "zone2"},
+		{CIDR: "4.1.1.0/24"},
+	} {
+		_, err := s.State.AddSubnet(info)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	space, err := s.State.AddSpace("my-space", "", []string{
+		"1.1.1.0/24", "2.1.1.0/24", "3.1.1.0/24", "4.1.1.0/24",
+	}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	zones, err := space.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(zones, jc.DeepEquals, []string{"zone1", "zone2"})
+}
+
+func (s *SpacesSuite) TestZonesNoSubnets(c *gc.C) {
+	space, err := s.State.AddSpace("my-space", "", nil, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	zones, err := space.Zones()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(zones, gc.HasLen, 0)
+}
+
+func (s *SpacesSuite) TestInUseNotUsed(c *gc.C) {
+	space, err := s.addSpaceWithSubnets(c, addSpaceArgs{
+		Name:        "my-space",
+		SubnetCIDRs: []string{"1.1.1.0/24"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	inUse, err := space.InUse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inUse, jc.IsFalse)
+}
+
+func (s *SpacesSuite) TestInUseByAddress(c *gc.C) {
+	space, err := s.addSpaceWithSubnets(c, addSpaceArgs{
+		Name:        "my-space",
+		SubnetCIDRs: []string{"1.1.1.0/24"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	subnets, closer := state.GetCollection(s.State, "subnets")
+	defer closer()
+	err = subnets.Writeable().UpdateId("1.1.1.0/24", bson.D{{"$set", bson.D{{"ref-count", 1}}}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	inUse, err := space.InUse()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(inUse, jc.IsTrue)
+}
+
+func (s *SpacesSuite) TestInUseByConstraints(c *gc.C) {
+	for i, cons := range []string{"spaces=my-space", "spaces=^my-space"} {
+		c.Logf("test %d: %s", i, cons)
+		space, err := s.State.AddSpace("my-space", "", nil, false, nil)
+		c.Assert(err, jc.ErrorIsNil)
+		machine, err := s.State.AddOneMachine(state.MachineTemplate{
+			Series:      "quantal",
+			Jobs:        []state.MachineJob{state.JobHostUnits},
+			Constraints: constraints.MustParse(cons),
+		})
+		c.Assert(err, jc.ErrorIsNil)
+
+		inUse, err := space.InUse()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(inUse, jc.IsTrue)
+
+		err = machine.EnsureDead()
+		c.Assert(err, jc.ErrorIsNil)
+		err = machine.Remove()
+		c.Assert(err, jc.ErrorIsNil)
+		inUse, err = space.InUse()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(inUse, jc.IsFalse)
+
+		s.ensureDeadAndAssertLifeIsDead(c, space)
+		err = space.Remove()
+		c.Assert(err, jc.ErrorIsNil)
+	}
+}
+
+func (s *SpacesSuite) TestEnsureSpaceCreates(c *gc.C) {
+	space, created, err := s.State.EnsureSpace("my-space", "provider-space", nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(created, jc.IsTrue)
+	c.Check(space.Name(), gc.Equals, "my-space")
+	c.Check(space.ProviderId(), gc.Equals, network.Id("provider-space"))
+}
+
+func (s *SpacesSuite) TestEnsureSpaceExisting(c *gc.C) {
+	existing, err := s.State.AddSpace("my-space", "provider-space", nil, true, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	space, created, err := s.State.EnsureSpace("my-space", "provider-space", nil, true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(created, jc.IsFalse)
+	c.Check(space.ID(), gc.Equals, existing.ID())
+}
+
+func (s *SpacesSuite) TestEnsureSpaceConflicting(c *gc.C) {
+	_, err := s.State.AddSpace("my-space", "provider-space", nil, true, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, _, err = s.State.EnsureSpace("my-space", "other-space", nil, true)
+	c.Check(err, gc.ErrorMatches, `ensuring space "my-space": space has provider id "provider-space", not "other-space"`)
+
+	_, _, err = s.State.EnsureSpace("my-space", "provider-space", nil, false)
+	c.Check(err, gc.ErrorMatches, `ensuring space "my-space": space has public flag true, not false`)
+}
+
+func (s *SpacesSuite) TestEnsureSpaceNotAlive(c *gc.C) {
+	space := s.addAliveSpace(c, "my-space")
+	s.ensureDeadAndAssertLifeIsDead(c, space)
+
+	_, _, err := s.State.EnsureSpace("my-space", "", nil, false)
+	c.Check(err, gc.ErrorMatches, `ensuring space "my-space": space is not alive`)
+}
+
+func (s *SpacesSuite) TestAddSpaceWithSubnets(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24"})
+	space, err := s.State.AddSpaceWithSubnets("my-space", "", []state.SubnetInfo{
+		{CIDR: "1.1.1.0/24"},
+		{CIDR: "2.1.1.0/24", AvailabilityZone: "zone1", ProviderId: "subnet-2"},
+	}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSpaceMatchesArgs(c, space, addSpaceArgs{
+		Name:        "my-space",
+		SubnetCIDRs: []string{"1.1.1.0/24", "2.1.1.0/24"},
+	})
+
+	subnet, err := s.State.Subnet("2.1.1.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.SpaceName(), gc.Equals, "my-space")
+	c.Check(subnet.AvailabilityZone(), gc.Equals, "zone1")
+	c.Check(subnet.ProviderId(), gc.Equals, network.Id("subnet-2"))
+}
+
+func (s *SpacesSuite) TestAddSpaceWithSubnetsOverlapping(c *gc.C) {
+	_, err := s.State.AddSpaceWithSubnets("my-space", "", []state.SubnetInfo{
+		{CIDR: "1.1.0.0/16"},
+		{CIDR: "1.1.1.0/24"},
+	}, false)
+	c.Assert(err, gc.ErrorMatches, `adding space "my-space": subnet "1.1.0.0/16" overlaps with subnet "1.1.1.0/24"`)
+	s.assertSpaceNotFound(c, "my-space")
+	_, err = s.State.Subnet("1.1.0.0/16")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SpacesSuite) TestAddSpaceWithSubnetsValidatesSubnets(c *gc.C) {
+	_, err := s.State.AddSpaceWithSubnets("my-space", "", []state.SubnetInfo{
+		{CIDR: "1.1.1.0/24", VLANTag: 4095},
+	}, false)
+	c.Assert(err, gc.ErrorMatches, `adding space "my-space": adding subnet "1.1.1.0/24": invalid VLAN tag 4095: .*`)
+	s.assertSpaceNotFound(c, "my-space")
+}
+
+func (s *SpacesSuite) TestAddSpaceWithSubnetsDuplicateProviderId(c *gc.C) {
+	_, err := s.State.AddSpaceWithSubnets("my-space", "", []state.SubnetInfo{
+		{CIDR: "1.1.1.0/24", ProviderId: "subnet-1"},
+		{CIDR: "2.1.1.0/24", ProviderId: "subnet-1"},
+	}, false)
+	c.Assert(err, gc.ErrorMatches, `adding space "my-space": adding subnet "2.1.1.0/24": ProviderId "subnet-1" not unique`)
+	s.assertSpaceNotFound(c, "my-space")
+}
+
+func (s *SpacesSuite) TestAddSpaceWithSubnetsProviderIdInUse(c *gc.C) {
+	_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "1.1.1.0/24", ProviderId: "subnet-1"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.State.AddSpaceWithSubnets("my-space", "", []state.SubnetInfo{
+		{CIDR: "2.1.1.0/24", ProviderId: "subnet-1"},
+	}, false)
+	c.Assert(err, gc.ErrorMatches, `adding space "my-space": adding subnet "2.1.1.0/24": ProviderId "subnet-1" not unique`)
+	s.assertSpaceNotFound(c, "my-space")
+	_, err = s.State.Subnet("2.1.1.0/24")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SpacesSuite) TestAddSpaceWithSubnetsRollsBackOnFailure(c *gc.C) {
+	s.addAliveSpace(c, "my-space")
+	_, err := s.State.AddSpaceWithSubnets("my-space", "", []state.SubnetInfo{
+		{CIDR: "1.1.1.0/24"},
+	}, false)
+	c.Assert(err, gc.ErrorMatches, `adding space "my-space": space "my-space" already exists`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	_, err = s.State.Subnet("1.1.1.0/24")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SpacesSuite) TestLabels(c *gc.C) {
+	spaces, err := s.State.AddSpaces([]state.SpaceSpec{{
+		Name:   "labelled",
+		Labels: map[string]string{"team": "net", "env": "prod"},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(spaces[0].Labels(), jc.DeepEquals, map[string]string{"team": "net", "env": "prod"})
+
+	space, err := s.State.Space("labelled")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Labels(), jc.DeepEquals, map[string]string{"team": "net", "env": "prod"})
+}
+
+func (s *SpacesSuite) TestAddSpaceWithLabels(c *gc.C) {
+	space, err := s.State.AddSpace("labelled", "", nil, false, map[string]string{"team": "net"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Labels(), jc.DeepEquals, map[string]string{"team": "net"})
+
+	err = space.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Labels(), jc.DeepEquals, map[string]string{"team": "net"})
+}
+
+func (s *SpacesSuite) TestLabelsEmpty(c *gc.C) {
+	space := s.addAliveSpace(c, "unlabelled")
+	c.Check(space.Labels(), jc.DeepEquals, map[string]string{})
+}
+
+func (s *SpacesSuite) TestSetLabels(c *gc.C) {
+	space := s.addAliveSpace(c, "my-space")
+
+	err := space.SetLabels(map[string]string{"cost-center": "42"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Labels(), jc.DeepEquals, map[string]string{"cost-center": "42"})
+	err = space.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Labels(), jc.DeepEquals, map[string]string{"cost-center": "42"})
+
+	err = space.SetLabels(nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = space.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Labels(), jc.DeepEquals, map[string]string{})
+}
+
+func (s *SpacesSuite) TestSetLabelsInvalidKey(c *gc.C) {
+	space := s.addAliveSpace(c, "my-space")
+
+	err := space.SetLabels(map[string]string{"a.b": "c"})
+	c.Assert(err, gc.ErrorMatches, `cannot set labels of space "my-space": label key "a.b" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *SpacesSuite) TestSetLabelsNotAlive(c *gc.C) {
+	space := s.addAliveSpace(c, "my-space")
+	s.ensureDeadAndAssertLifeIsDead(c, space)
+
+	err := space.SetLabels(map[string]string{"team": "net"})
+	c.Assert(err, gc.ErrorMatches, `cannot set labels of space "my-space": not found or not alive`)
+}
+
+func (s *SpacesSuite) TestAddSpaces(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24", "2.1.1.0/24", "3.1.1.0/24"})
+	specs := []state.SpaceSpec{{
+		Name:       "first",
+		ProviderId: network.Id("first id"),
+		Subnets:    []string{"1.1.1.0/24"},
+		IsPublic:   true,
+	}, {
+		Name:    "second",
+		Subnets: []string{"2.1.1.0/24", "3.1.1.0/24"},
+	}}
+	spaces, err := s.State.AddSpaces(specs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, gc.HasLen, 2)
+	for i, spec := range specs {
+		s.assertSpaceMatchesArgs(c, spaces[i], addSpaceArgs{
+			Name:        spec.Name,
+			ProviderId:  spec.ProviderId,
+			SubnetCIDRs: spec.Subnets,
+			IsPublic:    spec.IsPublic,
+		})
+	}
+}
+
+func (s *SpacesSuite) TestAddSpacesRollsBackOnFailure(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24"})
+	s.addAliveSpace(c, "existing")
+	specs := []state.SpaceSpec{{
+		Name:    "new",
+		Subnets: []string{"1.1.1.0/24"},
+	}, {
+		Name: "existing",
+	}}
+	_, err := s.State.AddSpaces(specs)
+	c.Assert(err, gc.ErrorMatches, `adding space "existing": space "existing" already exists`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+
+	s.assertSpaceNotFound(c, "new")
+	subnet, err := s.State.Subnet("1.1.1.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnet.SpaceName(), gc.Equals, "")
+}
+
+func (s *SpacesSuite) TestAddSpacesRejectsDuplicates(c *gc.C) {
+	_, err := s.State.AddSpaces([]state.SpaceSpec{{Name: "dup"}, {Name: "dup"}})
+	c.Assert(err, gc.ErrorMatches, `adding space "dup": space specified more than once`)
+
+	_, err = s.State.AddSpaces([]state.SpaceSpec{{Name: "dup"}, {Name: "DUP"}})
+	c.Assert(err, gc.ErrorMatches, `adding space "DUP": space specified more than once`)
+	s.assertSpaceNotFound(c, "dup")
+
+	_, err = s.State.AddSpaces([]state.SpaceSpec{
+		{Name: "one", Subnets: []string{"1.1.1.0/24"}},
+		{Name: "two", Subnets: []string{"1.1.1.0/24"}},
+	})
+	c.Assert(err, gc.ErrorMatches, `adding space "two": subnet "1.1.1.0/24" already specified for space "one"`)
+
+	_, err = s.State.AddSpaces([]state.SpaceSpec{
+		{Name: "one", ProviderId: "same"},
+		{Name: "two", ProviderId: "same"},
+	})
+	c.Assert(err, gc.ErrorMatches, `adding space "two": ProviderId "same" not unique`)
+	s.assertSpaceNotFound(c, "one")
+}
+
 func (s *SpacesSuite) TestAllSpaces(c *gc.C) {
 	spaces, err := s.State.AllSpaces()
 	c.Assert(err, jc.ErrorIsNil)
@@ -485,11 +928,11 @@ func (s *SpacesSuite) TestAllSpaces(c *gc.C) {
 	isPublic := false
 	s.addSubnets(c, subnets)
 
-	first, err := s.State.AddSpace("first", "", []string{"1.1.1.0/24"}, isPublic)
+	first, err := s.State.AddSpace("first", "", []string{"1.1.1.0/24"}, isPublic, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	second, err := s.State.AddSpace("second", "", []string{"2.1.1.0/24"}, isPublic)
+	second, err := s.State.AddSpace("second", "", []string{"2.1.1.0/24"}, isPublic, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	third, err := s.State.AddSpace("third", "", []string{"3.1.1.0/24"}, isPublic)
+	third, err := s.State.AddSpace("third", "", []string{"3.1.1.0/24"}, isPublic, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	actual, err := s.State.AllSpaces()
@@ -497,6 +940,362 @@ func (s *SpacesSuite) TestAllSpaces(c *gc.C) {
 	c.Assert(actual, jc.SameContents, []*state.Space{first, second, third})
 }
 
+func (s *SpacesSuite) TestSpaceCount(c *gc.C) {
+	count, err := s.State.SpaceCount()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 0)
+
+	s.addAliveSpace(c, "first")
+	s.addAliveSpace(c, "second")
+	otherState := s.NewStateForModelNamed(c, "other")
+	_, err = otherState.AddSpace("third", "", nil, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	count, err = s.State.SpaceCount()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 2)
+}
+
+func (s *SpacesSuite) TestSpacesIter(c *gc.C) {
+	for _, name := range []string{"third", "first", "second"} {
+		s.addAliveSpace(c, name)
+	}
+
+	iter, err := s.State.SpacesIter()
+	c.Assert(err, jc.ErrorIsNil)
+	var names []string
+	var space state.Space
+	for iter.Next(&space) {
+		names = append(names, space.Name())
+	}
+	c.Assert(iter.Err(), jc.ErrorIsNil)
+	c.Assert(iter.Close(), jc.ErrorIsNil)
+	c.Assert(names, jc.DeepEquals, []string{"first", "second", "third"})
+}
+
+func (s *SpacesSuite) TestSpacesIterStopEarly(c *gc.C) {
+	s.addAliveSpace(c, "first")
+	s.addAliveSpace(c, "second")
+
+	iter, err := s.State.SpacesIter()
+	c.Assert(err, jc.ErrorIsNil)
+	var space state.Space
+	c.Assert(iter.Next(&space), jc.IsTrue)
+	c.Assert(space.Name(), gc.Equals, "first")
+	c.Assert(space.Refresh(), jc.ErrorIsNil)
+	c.Assert(iter.Close(), jc.ErrorIsNil)
+}
+
+func (s *SpacesSuite) TestAllSpacesPaged(c *gc.C) {
+	for _, name := range []string{"delta", "alpha", "echo", "charlie", "bravo"} {
+		_, err := s.State.AddSpace(name, "", nil, false, nil)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	spaceNames := func(spaces []*state.Space) []string {
+		result := make([]string, len(spaces))
+		for i, space := range spaces {
+			result[i] = space.Name()
+		}
+		return result
+	}
+
+	page, err := s.State.AllSpacesPaged(0, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(spaceNames(page), jc.DeepEquals, []string{"alpha", "bravo"})
+
+	page, err = s.State.AllSpacesPaged(2, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(spaceNames(page), jc.DeepEquals, []string{"charlie", "delta"})
+
+	page, err = s.State.AllSpacesPaged(4, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(spaceNames(page), jc.DeepEquals, []string{"echo"})
+
+	page, err = s.State.AllSpacesPaged(6, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(page, gc.HasLen, 0)
+}
+
+func (s *SpacesSuite) TestAllSpacesPagedInvalidArgs(c *gc.C) {
+	_, err := s.State.AllSpacesPaged(-1, 2)
+	c.Check(err, gc.ErrorMatches, "negative offset -1 not valid")
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+
+	_, err = s.State.AllSpacesPaged(0, 0)
+	c.Check(err, gc.ErrorMatches, "non-positive limit 0 not valid")
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *SpacesSuite) TestSpaceCreated(c *gc.C) {
+	before := state.NowToTheSecond()
+	space, err := s.State.AddSpace("my-space", "", nil, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	after := state.NowToTheSecond()
+
+	created := space.Created()
+	c.Check(created.Before(before), jc.IsFalse)
+	c.Check(created.After(after), jc.IsFalse)
+	c.Check(created.Location(), gc.Equals, time.UTC)
+
+	space, err = s.State.Space("my-space")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Created().Equal(created), jc.IsTrue)
+}
+
+func (s *SpacesSuite) TestSpaceCreatedMissing(c *gc.C) {
+	space, err := s.State.AddSpace("my-space", "", nil, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Spaces added before creation times were recorded
+	// have no "created" field.
+	spaces, closer := state.GetCollection(s.State, "spaces")
+	defer closer()
+	err = spaces.Writeable().UpdateId(space.ID(), bson.D{{"$unset", bson.D{{"created", 1}}}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = space.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(space.Created().IsZero(), jc.IsTrue)
+}
+
+func (s *SpacesSuite) TestRenameSpace(c *gc.C) {
+	args := addSpaceArgs{
+		Name:        "old-name",
+		ProviderId:  network.Id("provider id"),
+		SubnetCIDRs: []string{"1.1.1.0/24", "2.1.1.0/24"},
+		IsPublic:    true,
+	}
+	_, err := s.addSpaceWithSubnets(c, args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RenameSpace("old-name", "new-name")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.assertSpaceNotFound(c, "old-name")
+	space, err := s.State.Space("new-name")
+	c.Assert(err, jc.ErrorIsNil)
+	args.Name = "new-name"
+	s.assertSpaceMatchesArgs(c, space, args)
+	for _, cidr := range args.SubnetCIDRs {
+		subnet, err := s.State.Subnet(cidr)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Assert(subnet.SpaceName(), gc.Equals, "new-name")
+	}
+}
+
+func (s *SpacesSuite) TestRenameSpaceUpdatesConstraintsAndBindings(c *gc.C) {
+	s.addAliveSpace(c, "old-name")
+	s.addAliveSpace(c, "other")
+	err := s.State.SetModelConstraints(constraints.MustParse("spaces=old-name,^other"))
+	c.Assert(err, jc.ErrorIsNil)
+	ch := s.AddTestingCharm(c, "mysql")
+	service := s.AddTestingServiceWithBindings(c, "mysql", ch, map[string]string{"server": "old-name"})
+	err = service.SetConstraints(constraints.MustParse("spaces=^old-name"))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.State.RenameSpace("old-name", "new-name")
+	c.Assert(err, jc.ErrorIsNil)
+
+	cons, err := s.State.ModelConstraints()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(*cons.Spaces, jc.DeepEquals, []string{"new-name", "^other"})
+	cons, err = service.Constraints()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(*cons.Spaces, jc.DeepEquals, []string{"^new-name"})
+	bindings, err := service.EndpointBindings()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(bindings["server"], gc.Equals, "new-name")
+}
+
+func (s *SpacesSuite) insertLegacySpace(c *gc.C, name string) {
+	// Space names are validated as lower case, but spaces added
+	// before that was enforced may not be.
+	spaces, closer := state.GetCollection(s.State, "spaces")
+	defer closer()
+	err := spaces.Writeable().Insert(bson.D{
+		{"_id", name},
+		{"name", name},
+		{"life", state.Alive},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *SpacesSuite) TestRenameSpaceToExistingNameDifferentCaseFails(c *gc.C) {
+	s.addAliveSpace(c, "first")
+	s.insertLegacySpace(c, "Second")
+
+	err := s.State.RenameSpace("first", "second")
+	c.Assert(err, gc.ErrorMatches, `renaming space "first" to "second": space "second" already exists as "Second"`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	_, err = s.State.Space("first")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *SpacesSuite) TestRenameSpaceChangingOnlyCase(c *gc.C) {
+	s.insertLegacySpace(c, "First")
+
+	err := s.State.RenameSpace("First", "first")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSpaceNotFound(c, "First")
+	_, err = s.State.Space("first")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *SpacesSuite) TestRenameSpaceToExistingNameFails(c *gc.C) {
+	s.addAliveSpace(c, "first")
+	s.addAliveSpace(c, "second")
+
+	err := s.State.RenameSpace("first", "second")
+	c.Assert(err, gc.ErrorMatches, `renaming space "first" to "second": space "second" already exists`)
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+	_, err = s.State.Space("first")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *SpacesSuite) TestRenameSpaceWithInvalidNameFails(c *gc.C) {
+	s.addAliveSpace(c, "first")
+
+	err := s.State.RenameSpace("first", "-bad name-")
+	c.Assert(err, gc.ErrorMatches, `renaming space "first" to "-bad name-": invalid space name`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *SpacesSuite) TestRenameSpaceNotAliveFails(c *gc.C) {
+	space := s.addAliveSpace(c, "dead")
+	s.ensureDeadAndAssertLifeIsDead(c, space)
+
+	err := s.State.RenameSpace("dead", "alive")
+	c.Assert(err, gc.ErrorMatches, `renaming space "dead" to "alive": space "dead" is not alive`)
+	s.assertSpaceNotFound(c, "alive")
+}
+
+func (s *SpacesSuite) TestRenameSpaceNotFound(c *gc.C) {
+	err := s.State.RenameSpace("missing", "other")
+	c.Assert(err, gc.ErrorMatches, `renaming space "missing" to "other": space "missing" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SpacesSuite) TestMoveSubnetsFrom(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24", "2.1.1.0/24", "3.1.1.0/24"})
+	from, err := s.State.AddSpace("from", "", []string{"1.1.1.0/24", "2.1.1.0/24", "3.1.1.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	to := s.addAliveSpace(c, "to")
+
+	err = to.MoveSubnetsFrom(from, []string{"1.1.1.0/24", "3.1.1.0/24"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for cidr, spaceName := range map[string]string{
+		"1.1.1.0/24": "to",
+		"2.1.1.0/24": "from",
+		"3.1.1.0/24": "to",
+	} {
+		subnet, err := s.State.Subnet(cidr)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(subnet.SpaceName(), gc.Equals, spaceName)
+	}
+}
+
+func (s *SpacesSuite) TestMoveSubnetsFromWrongSpaceFails(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24", "2.1.1.0/24"})
+	from, err := s.State.AddSpace("from", "", []string{"1.1.1.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.AddSpace("elsewhere", "", []string{"2.1.1.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	to := s.addAliveSpace(c, "to")
+
+	err = to.MoveSubnetsFrom(from, []string{"1.1.1.0/24", "2.1.1.0/24"})
+	c.Assert(err, gc.ErrorMatches,
+		`moving subnets from space "from" to "to": subnet "2.1.1.0/24" is not in space "from"`,
+	)
+
+	// Nothing was moved.
+	subnet, err := s.State.Subnet("1.1.1.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnet.SpaceName(), gc.Equals, "from")
+}
+
+func (s *SpacesSuite) TestMoveSubnetsFromToDeadSpaceFails(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24"})
+	from, err := s.State.AddSpace("from", "", []string{"1.1.1.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	to := s.addAliveSpace(c, "to")
+	s.ensureDeadAndAssertLifeIsDead(c, to)
+
+	err = to.MoveSubnetsFrom(from, []string{"1.1.1.0/24"})
+	c.Assert(err, gc.ErrorMatches, `moving subnets from space "from" to "to": space "to" is not alive`)
+}
+
+func (s *SpacesSuite) TestSpaceByCIDR(c *gc.C) {
+	space, err := s.addSpaceWithSubnets(c, addSpaceArgs{
+		Name:        "space1",
+		SubnetCIDRs: []string{"1.1.1.0/24", "2.2.2.0/24"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	found, err := s.State.SpaceByCIDR("2.2.2.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found.Name(), gc.Equals, space.Name())
+}
+
+func (s *SpacesSuite) TestSpaceByCIDRUnassignedSubnet(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24"})
+
+	_, err := s.State.SpaceByCIDR("1.1.1.0/24")
+	c.Assert(err, gc.ErrorMatches, `space for subnet "1.1.1.0/24" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SpacesSuite) TestSpaceByCIDRSubnetNotFound(c *gc.C) {
+	_, err := s.State.SpaceByCIDR("1.1.1.0/24")
+	c.Assert(err, gc.ErrorMatches, `subnet "1.1.1.0/24" not found`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *SpacesSuite) TestSpacesByVisibility(c *gc.C) {
+	spaces, err := s.State.SpacesByVisibility(true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.DeepEquals, []*state.Space{})
+
+	public, err := s.State.AddSpace("public", "", nil, true, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	private1, err := s.State.AddSpace("private1", "", nil, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	private2, err := s.State.AddSpace("private2", "", nil, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	spaces, err = s.State.SpacesByVisibility(true)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.DeepEquals, []*state.Space{public})
+
+	spaces, err = s.State.SpacesByVisibility(false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.SameContents, []*state.Space{private1, private2})
+}
+
+func (s *SpacesSuite) TestProviderAndManualSpaces(c *gc.C) {
+	spaces, err := s.State.ProviderSpaces()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.DeepEquals, []*state.Space{})
+	spaces, err = s.State.ManualSpaces()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.DeepEquals, []*state.Space{})
+
+	maas1, err := s.State.AddSpace("maas1", network.Id("maas-1"), nil, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	maas2, err := s.State.AddSpace("maas2", network.Id("maas-2"), nil, true, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	manual, err := s.State.AddSpace("manual", "", nil, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	spaces, err = s.State.ProviderSpaces()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.SameContents, []*state.Space{maas1, maas2})
+
+	spaces, err = s.State.ManualSpaces()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spaces, jc.DeepEquals, []*state.Space{manual})
+}
+
 func (s *SpacesSuite) TestEnsureDeadSetsLifeToDeadWhenAlive(c *gc.C) {
 	space := s.addAliveSpace(c, "alive")
 
@@ -505,7 +1304,7 @@ func (s *SpacesSuite) TestEnsureDeadSetsLifeToDeadWhenAlive(c *gc.C) {
 }
 
 func (s *SpacesSuite) addAliveSpace(c *gc.C, name string) *state.Space {
-	space, err := s.State.AddSpace(name, "", nil, false)
+	space, err := s.State.AddSpace(name, "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(space.Life(), gc.Equals, state.Alive)
 	return space
@@ -523,6 +1322,37 @@ func (s *SpacesSuite) refreshAndAssertSpaceLifeIs(c *gc.C, space *state.Space, e
 	c.Assert(space.Life(), gc.Equals, expectedLife)
 }
 
+func (s *SpacesSuite) TestEnsureDeadFailsWithSubnets(c *gc.C) {
+	args := addSpaceArgs{
+		Name:        "with-subnets",
+		SubnetCIDRs: []string{"1.1.1.0/24"},
+	}
+	space, err := s.addSpaceWithSubnets(c, args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = space.EnsureDead()
+	c.Assert(err, gc.ErrorMatches, `cannot set space "with-subnets" to dead: space still has subnets: 1.1.1.0/24`)
+	s.refreshAndAssertSpaceLifeIs(c, space, state.Alive)
+}
+
+func (s *SpacesSuite) TestEnsureDeadFailsWhenSubnetMovedConcurrently(c *gc.C) {
+	s.addSubnets(c, []string{"1.1.1.0/24"})
+	from, err := s.State.AddSpace("from", "", []string{"1.1.1.0/24"}, false, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	space := s.addAliveSpace(c, "soon-dead")
+
+	defer state.SetBeforeHooks(c, s.State, func() {
+		other, err := s.State.Space("soon-dead")
+		c.Assert(err, jc.ErrorIsNil)
+		err = other.MoveSubnetsFrom(from, []string{"1.1.1.0/24"})
+		c.Assert(err, jc.ErrorIsNil)
+	}).Check()
+
+	err = space.EnsureDead()
+	c.Assert(err, gc.ErrorMatches, `cannot set space "soon-dead" to dead: space still has subnets: 1.1.1.0/24`)
+	s.refreshAndAssertSpaceLifeIs(c, space, state.Alive)
+}
+
 func (s *SpacesSuite) TestEnsureDeadSetsLifeToDeadWhenNotAlive(c *gc.C) {
 	space := s.addAliveSpace(c, "soon-dead")
 	s.ensureDeadAndAssertLifeIsDead(c, space)
@@ -561,6 +1391,98 @@ func (s *SpacesSuite) TestRemoveSucceedsWhenCalledTwice(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `cannot remove space "twice-deleted": not found or not dead`)
 }
 
+func (s *SpacesSuite) TestRemoveUnassignsDanglingSubnets(c *gc.C) {
+	space := s.addAliveSpace(c, "dangling")
+	s.ensureDeadAndAssertLifeIsDead(c, space)
+
+	_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "1.1.1.0/24"})
+	c.Assert(err, jc.ErrorIsNil)
+	subnets, closer := state.GetCollection(s.State, "subnets")
+	defer closer()
+	err = subnets.Writeable().UpdateId("1.1.1.0/24", bson.D{{"$set", bson.D{{"space-name", "dangling"}}}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.removeSpaceAndAssertNotFound(c, space)
+
+	subnet, err := s.State.Subnet("1.1.1.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnet.SpaceName(), gc.Equals, "")
+}
+
+func (s *SpacesSuite) TestForceRemoveFailsIfStillAlive(c *gc.C) {
+	space := s.addAliveSpace(c, "still-alive")
+
+	err := space.ForceRemove()
+	c.Assert(err, gc.ErrorMatches, `cannot force remove space "still-alive": space is not dead`)
+
+	s.refreshAndAssertSpaceLifeIs(c, space, state.Alive)
+}
+
+func (s *SpacesSuite) TestForceRemoveReleasesProviderId(c *gc.C) {
+	args := addSpaceArgs{Name: "stuck", ProviderId: network.Id("provider-stuck")}
+	space, err := s.addSpaceWithSubnets(c, args)
+	c.Assert(err, jc.ErrorIsNil)
+	s.ensureDeadAndAssertLifeIsDead(c, space)
+
+	err = space.ForceRemove()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSpaceNotFound(c, "stuck")
+
+	// The provider id can be reused.
+	args.Name = "unstuck"
+	_, err = s.addSpaceWithSubnets(c, args)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *SpacesSuite) TestForceRemoveWithProviderIdAlreadyGone(c *gc.C) {
+	space, err := s.addSpaceWithSubnets(c, addSpaceArgs{
+		Name:       "stuck",
+		ProviderId: network.Id("provider-stuck"),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.ensureDeadAndAssertLifeIsDead(c, space)
+
+	providerIDs, closer := state.GetCollection(s.State, "providerIDs")
+	defer closer()
+	err = providerIDs.Writeable().RemoveId("space:provider-stuck")
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = space.ForceRemove()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSpaceNotFound(c, "stuck")
+}
+
+func (s *SpacesSuite) TestForceRemoveUnassignsDanglingSubnets(c *gc.C) {
+	space := s.addAliveSpace(c, "dangling")
+	s.ensureDeadAndAssertLifeIsDead(c, space)
+
+	_, err := s.State.AddSubnet(state.SubnetInfo{CIDR: "1.1.1.0/24"})
+	c.Assert(err, jc.ErrorIsNil)
+	subnets, closer := state.GetCollection(s.State, "subnets")
+	defer closer()
+	err = subnets.Writeable().UpdateId("1.1.1.0/24", bson.D{{"$set", bson.D{{"space-name", "dangling"}}}})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = space.ForceRemove()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSpaceNotFound(c, "dangling")
+
+	subnet, err := s.State.Subnet("1.1.1.0/24")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnet.SpaceName(), gc.Equals, "")
+}
+
+func (s *SpacesSuite) TestForceRemoveSucceedsWhenCalledTwice(c *gc.C) {
+	space := s.addAliveSpace(c, "twice-deleted")
+	s.ensureDeadAndAssertLifeIsDead(c, space)
+
+	err := space.ForceRemove()
+	c.Assert(err, jc.ErrorIsNil)
+	err = space.ForceRemove()
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertSpaceNotFound(c, "twice-deleted")
+}
+
 func (s *SpacesSuite) TestRefreshUpdatesStaleDocData(c *gc.C) {
 	space := s.addAliveSpace(c, "original")
 	spaceCopy, err := s.State.Space(space.Name())
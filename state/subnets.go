@@ -6,6 +6,7 @@ package state
 import (
 	"math/rand"
 	"net"
+	"strings"
 
 	"github.com/juju/errors"
 	"gopkg.in/mgo.v2"
@@ -62,6 +63,89 @@ type subnetDoc struct {
 	IsPublic          bool   `bson:"is-public,omitempty"`
 	// TODO(dooferlad 2015-08-03): add an upgrade step to insert IsPublic=false
 	SpaceName string `bson:"space-name,omitempty"`
+
+	// RefCount is the number of link-layer device IP addresses in the
+	// subnet. Documents created before reference counting was introduced
+	// have no ref-count field, which reads as zero.
+	RefCount int `bson:"ref-count,omitempty"`
+}
+
+// subnetHasNoRefsAssert matches subnet documents which are not referred to
+// by any IP address, including those without a ref-count field.
+var subnetHasNoRefsAssert = bson.D{{"ref-count", bson.D{{"$not", bson.D{{"$gt", 0}}}}}}
+
+// adjustSubnetRefCountOp returns an operation increasing the RefCount of
+// the subnet with the given CIDR by delta, asserting the subnet is alive.
+func adjustSubnetRefCountOp(st *State, cidr string, delta int) txn.Op {
+	return txn.Op{
+		C:      subnetsC,
+		Id:     st.docID(cidr),
+		Assert: isAliveDoc,
+		Update: bson.D{{"$inc", bson.D{{"ref-count", delta}}}},
+	}
+}
+
+// releaseSubnetRefsOp returns an operation decreasing the RefCount of the
+// given subnet by n. Addresses that existed before reference counting was
+// introduced were never counted, so rather than failing when the count is
+// too low, the count is clamped at zero. The subnet's current count decides
+// which of the two updates is used, and is asserted so that a concurrent
+// change causes the transaction to be rebuilt.
+func releaseSubnetRefsOp(subnet *Subnet, n int) txn.Op {
+	if subnet.doc.RefCount >= n {
+		return txn.Op{
+			C:      subnetsC,
+			Id:     subnet.doc.DocID,
+			Assert: bson.D{{"ref-count", bson.D{{"$gte", n}}}},
+			Update: bson.D{{"$inc", bson.D{{"ref-count", -n}}}},
+		}
+	}
+	return txn.Op{
+		C:      subnetsC,
+		Id:     subnet.doc.DocID,
+		Assert: bson.D{{"ref-count", bson.D{{"$not", bson.D{{"$gte", n}}}}}},
+		Update: bson.D{{"$set", bson.D{{"ref-count", 0}}}},
+	}
+}
+
+// countSubnetAddresses returns the number of IP addresses in the subnet
+// with the given CIDR.
+func (st *State) countSubnetAddresses(cidr string) (int, error) {
+	addresses, closer := st.getCollection(ipAddressesC)
+	defer closer()
+
+	count, err := addresses.Find(bson.D{{"subnet-cidr", cidr}}).Count()
+	if err != nil {
+		return 0, errors.Annotatef(err, "cannot count addresses in subnet %q", cidr)
+	}
+	return count, nil
+}
+
+// subnetUsers returns the ids of the machines with IP addresses in the
+// subnet with the given CIDR.
+func (st *State) subnetUsers(cidr string) ([]string, error) {
+	var machineIds []string
+	seen := make(map[string]bool)
+	callbackFunc := func(resultDoc *ipAddressDoc) {
+		if !seen[resultDoc.MachineID] {
+			seen[resultDoc.MachineID] = true
+			machineIds = append(machineIds, resultDoc.MachineID)
+		}
+	}
+	if err := st.forEachIPAddressDoc(bson.D{{"subnet-cidr", cidr}}, callbackFunc); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return machineIds, nil
+}
+
+// subnetInUseError returns an error naming the machines using the subnet
+// with the given CIDR.
+func (st *State) subnetInUseError(cidr string) error {
+	machineIds, err := st.subnetUsers(cidr)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Errorf("subnet %q is in use by machines %s", cidr, strings.Join(machineIds, ", "))
 }
 
 // Life returns whether the subnet is Alive, Dying or Dead.
@@ -365,54 +449,25 @@ var pickAddress = func(low, high uint32, allocated map[uint32]bool) uint32 {
 func (st *State) AddSubnet(args SubnetInfo) (subnet *Subnet, err error) {
 	defer errors.DeferredAnnotatef(&err, "adding subnet %q", args.CIDR)
 
-	subnetID := st.docID(args.CIDR)
-	subDoc := subnetDoc{
-		DocID:             subnetID,
-		ModelUUID:         st.ModelUUID(),
-		Life:              Alive,
-		CIDR:              args.CIDR,
-		VLANTag:           args.VLANTag,
-		ProviderId:        string(args.ProviderId),
-		AllocatableIPHigh: args.AllocatableIPHigh,
-		AllocatableIPLow:  args.AllocatableIPLow,
-		AvailabilityZone:  args.AvailabilityZone,
-		SpaceName:         args.SpaceName,
-	}
-	subnet = &Subnet{doc: subDoc, st: st}
-	err = subnet.Validate()
+	subnet, err = st.newSubnet(args)
 	if err != nil {
 		return nil, err
 	}
 
 	buildTxn := func(attempt int) ([]txn.Op, error) {
-		ops := []txn.Op{
-			assertModelActiveOp(st.ModelUUID()),
-			{
-				C:      subnetsC,
-				Id:     subnetID,
-				Assert: txn.DocMissing,
-				Insert: subDoc,
-			},
-		}
-		if args.ProviderId != "" {
-			ops = append(ops, st.networkEntityGlobalKeyOp("subnet", args.ProviderId))
-		}
-
 		if attempt != 0 {
 			if err := checkModelActive(st); err != nil {
 				return nil, errors.Trace(err)
 			}
-			if _, err = st.Subnet(args.CIDR); err == nil {
-				return nil, errors.AlreadyExistsf("subnet %q", args.CIDR)
-			}
-			if err := subnet.Refresh(); err != nil {
-				if errors.IsNotFound(err) {
-					return nil, errors.Errorf("ProviderId %q not unique", args.ProviderId)
-				}
-				return nil, errors.Trace(err)
+			if err := st.addSubnetAbortedError(subnet); err != nil {
+				return nil, err
 			}
 		}
-		return ops, nil
+		subnetOps, err := st.addSubnetOps(subnet)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append([]txn.Op{assertModelActiveOp(st.ModelUUID())}, subnetOps...), nil
 	}
 	err = st.run(buildTxn)
 	if err != nil {
@@ -421,6 +476,65 @@ func (st *State) AddSubnet(args SubnetInfo) (subnet *Subnet, err error) {
 	return subnet, nil
 }
 
+// newSubnet returns the subnet described by args, without adding it to
+// state, or an error if args are not valid.
+func (st *State) newSubnet(args SubnetInfo) (*Subnet, error) {
+	subnet := &Subnet{st: st, doc: subnetDoc{
+		DocID:             st.docID(args.CIDR),
+		ModelUUID:         st.ModelUUID(),
+		Life:              Alive,
+		CIDR:              args.CIDR,
+		VLANTag:           args.VLANTag,
+		ProviderId:        string(args.ProviderId),
+		AllocatableIPHigh: args.AllocatableIPHigh,
+		AllocatableIPLow:  args.AllocatableIPLow,
+		AvailabilityZone:  args.AvailabilityZone,
+		SpaceName:         args.SpaceName,
+	}}
+	if err := subnet.Validate(); err != nil {
+		return nil, err
+	}
+	return subnet, nil
+}
+
+// addSubnetOps returns the operations needed to insert the given subnet,
+// returned by newSubnet, and to reserve its ProviderId if it has one.
+func (st *State) addSubnetOps(subnet *Subnet) ([]txn.Op, error) {
+	// Addresses may already have been added in the subnet's CIDR
+	// before the subnet itself was known, so count them now.
+	refCount, err := st.countSubnetAddresses(subnet.CIDR())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	subnet.doc.RefCount = refCount
+
+	ops := []txn.Op{{
+		C:      subnetsC,
+		Id:     subnet.doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: subnet.doc,
+	}}
+	if subnet.doc.ProviderId != "" {
+		ops = append(ops, st.networkEntityGlobalKeyOp("subnet", subnet.ProviderId()))
+	}
+	return ops, nil
+}
+
+// addSubnetAbortedError returns the reason a transaction including the
+// operations returned by addSubnetOps for the given subnet was aborted,
+// or nil if the subnet was not the cause.
+func (st *State) addSubnetAbortedError(subnet *Subnet) error {
+	if _, err := st.Subnet(subnet.CIDR()); err == nil {
+		return errors.AlreadyExistsf("subnet %q", subnet.CIDR())
+	} else if !errors.IsNotFound(err) {
+		return errors.Trace(err)
+	}
+	if subnet.doc.ProviderId != "" {
+		return errors.Errorf("ProviderId %q not unique", subnet.doc.ProviderId)
+	}
+	return nil
+}
+
 // Subnet returns the subnet specified by the cidr.
 func (st *State) Subnet(cidr string) (*Subnet, error) {
 	subnets, closer := st.getCollection(subnetsC)
@@ -437,6 +551,25 @@ func (st *State) Subnet(cidr string) (*Subnet, error) {
 	return &Subnet{st, *doc}, nil
 }
 
+// UnassignedSubnets returns the subnets in the model that are not in any
+// space, and so cannot be used for space-aware placement. An empty slice
+// is returned if every subnet is in a space.
+func (st *State) UnassignedSubnets() ([]*Subnet, error) {
+	subnetsCollection, closer := st.getCollection(subnetsC)
+	defer closer()
+
+	docs := []subnetDoc{}
+	query := bson.D{{"space-name", bson.D{{"$in", []interface{}{nil, ""}}}}}
+	if err := subnetsCollection.Find(query).All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get unassigned subnets")
+	}
+	subnets := make([]*Subnet, len(docs))
+	for i, doc := range docs {
+		subnets[i] = &Subnet{st, doc}
+	}
+	return subnets, nil
+}
+
 // AllSubnets returns all known subnets in the model.
 func (st *State) AllSubnets() (subnets []*Subnet, err error) {
 	subnetsCollection, closer := st.getCollection(subnetsC)
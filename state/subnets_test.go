@@ -357,6 +357,30 @@ func (s *SubnetSuite) TestAllSubnets(c *gc.C) {
 	}
 }
 
+func (s *SubnetSuite) TestUnassignedSubnets(c *gc.C) {
+	subnets, err := s.State.UnassignedSubnets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnets, gc.HasLen, 0)
+	c.Assert(subnets, gc.NotNil)
+
+	for _, info := range []state.SubnetInfo{
+		{CIDR: "192.168.1.0/24"},
+		{CIDR: "8.8.8.0/24", SpaceName: "bar"},
+		{CIDR: "10.0.2.0/24", ProviderId: "foo"},
+	} {
+		_, err := s.State.AddSubnet(info)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	subnets, err = s.State.UnassignedSubnets()
+	c.Assert(err, jc.ErrorIsNil)
+	var cidrs []string
+	for _, subnet := range subnets {
+		cidrs = append(cidrs, subnet.CIDR())
+	}
+	c.Assert(cidrs, jc.SameContents, []string{"192.168.1.0/24", "10.0.2.0/24"})
+}
+
 func (s *SubnetSuite) TestPickNewAddressNoAddresses(c *gc.C) {
 	subnet := s.addAliveSubnet(c, "192.168.1.0/24")
 
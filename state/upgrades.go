@@ -249,3 +249,36 @@ func addDefaultBindingsToServices(st *State) error {
 func AddDefaultEndpointBindingsToServices(st *State) error {
 	return runForAllEnvStates(st, addDefaultBindingsToServices)
 }
+
+func addSubnetRefCounts(st *State) error {
+	subnets, err := st.AllSubnets()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	upgradesLogger.Debugf("adding reference counts to subnets")
+	ops := make([]txn.Op, 0, len(subnets))
+	for _, subnet := range subnets {
+		refCount, err := st.countSubnetAddresses(subnet.CIDR())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		ops = append(ops, txn.Op{
+			C:      subnetsC,
+			Id:     subnet.doc.DocID,
+			Assert: txn.DocExists,
+			Update: bson.D{{"$set", bson.D{{"ref-count", refCount}}}},
+		})
+	}
+	if len(ops) > 0 {
+		return errors.Trace(st.runTransaction(ops))
+	}
+	return nil
+}
+
+// AddSubnetRefCounts sets the reference count of each subnet to the number
+// of IP addresses in it. Addresses added before reference counting was
+// introduced were never counted.
+func AddSubnetRefCounts(st *State) error {
+	return runForAllEnvStates(st, addSubnetRefCounts)
+}
@@ -4,6 +4,7 @@
 package state
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/juju/errors"
@@ -468,9 +469,9 @@ func (s *upgradesSuite) setupAddDefaultEndpointBindingsToServices(c *gc.C) []*Se
 	c.Assert(err, jc.ErrorIsNil)
 
 	// Add a couple of test spaces
-	_, err = s.state.AddSpace("db", "", nil, false)
+	_, err = s.state.AddSpace("db", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.state.AddSpace("apps", "", nil, true)
+	_, err = s.state.AddSpace("apps", "", nil, true, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	// Add some testing charms for the services.
@@ -586,3 +587,48 @@ func (s *upgradesSuite) TestAddDefaultEndpointBindingsToServices(c *gc.C) {
 func (s *upgradesSuite) TestAddDefaultEndpointBindingsToServicesIdempotent(c *gc.C) {
 	s.testAddDefaultEndpointBindingsToServices(c, true)
 }
+
+func (s *upgradesSuite) TestAddSubnetRefCounts(c *gc.C) {
+	for _, cidr := range []string{"10.0.0.0/24", "10.0.1.0/24"} {
+		_, err := s.state.AddSubnet(SubnetInfo{CIDR: cidr})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	uuid := s.state.ModelUUID()
+	for i, cidr := range []string{"10.0.0.0/24", "10.0.0.0/24", "10.0.1.0/24"} {
+		s.addLegacyDoc(c, ipAddressesC, bson.M{
+			"_id":         s.state.docID(fmt.Sprintf("m#0#d#eth%d#ip#addr", i)),
+			"model-uuid":  uuid,
+			"subnet-cidr": cidr,
+		})
+	}
+
+	assertRefCount := func(cidr string, expected int) {
+		subnet, err := s.state.Subnet(cidr)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(subnet.doc.RefCount, gc.Equals, expected)
+	}
+	assertRefCount("10.0.0.0/24", 0)
+	assertRefCount("10.0.1.0/24", 0)
+
+	err := AddSubnetRefCounts(s.state)
+	c.Assert(err, jc.ErrorIsNil)
+	assertRefCount("10.0.0.0/24", 2)
+	assertRefCount("10.0.1.0/24", 1)
+
+	err = AddSubnetRefCounts(s.state)
+	c.Assert(err, jc.ErrorIsNil, gc.Commentf("idempotency check failed!"))
+	assertRefCount("10.0.0.0/24", 2)
+	assertRefCount("10.0.1.0/24", 1)
+}
+
+func (s *upgradesSuite) TestAddSubnetRefCountsNoSubnets(c *gc.C) {
+	subnets, err := s.state.AllSubnets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(subnets, gc.HasLen, 0)
+
+	err = AddSubnetRefCounts(s.state)
+	c.Assert(err, jc.ErrorIsNil)
+	subnets, err = s.state.AllSubnets()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(subnets, gc.HasLen, 0)
+}
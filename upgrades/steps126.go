@@ -73,5 +73,12 @@ func stateStepsFor126() []Step {
 				return state.AddDefaultEndpointBindingsToServices(context.State())
 			},
 		},
+		&upgradeStep{
+			description: "add reference counts to subnets",
+			targets:     []Target{DatabaseMaster},
+			run: func(context Context) error {
+				return state.AddSubnetRefCounts(context.State())
+			},
+		},
 	}
 }
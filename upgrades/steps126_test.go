@@ -29,6 +29,7 @@ func (s *steps126Suite) TestStateStepsFor126(c *gc.C) {
 		"provider side upgrades",
 		"update machine preferred addresses",
 		"add default endpoint bindings to services",
+		"add reference counts to subnets",
 	}
 	assertStateSteps(c, version.MustParse("1.26.0"), expected)
 }
@@ -951,9 +951,9 @@ func (s *ProvisionerSuite) TestProvisioningMachinesWithSpacesSuccess(c *gc.C) {
 	defer stop(c, p)
 
 	// Add the spaces used in constraints.
-	_, err := s.State.AddSpace("space1", "", nil, false)
+	_, err := s.State.AddSpace("space1", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
-	_, err = s.State.AddSpace("space2", "", nil, false)
+	_, err = s.State.AddSpace("space2", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	// Add 1 subnet into space1, and 2 into space2.
@@ -1050,7 +1050,7 @@ func (s *ProvisionerSuite) TestProvisioningMachinesFailsWithUnknownSpaces(c *gc.
 }
 
 func (s *ProvisionerSuite) TestProvisioningMachinesFailsWithEmptySpaces(c *gc.C) {
-	_, err := s.State.AddSpace("empty", "", nil, false)
+	_, err := s.State.AddSpace("empty", "", nil, false, nil)
 	c.Assert(err, jc.ErrorIsNil)
 	cons := constraints.MustParse(
 		s.defaultConstraints.String(), "spaces=empty",